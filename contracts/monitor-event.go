@@ -0,0 +1,44 @@
+package contracts
+
+import "time"
+
+// MonitorEventType -
+type MonitorEventType int
+
+// MonitorEventStarted -
+const (
+	MonitorEventStarted  MonitorEventType = iota // a monitored process was started
+	MonitorEventExited                           // a monitored process exited, see `MonitorEvent.Result`
+	MonitorEventWatchdog                         // a monitored process went over its watchdog limits, see `MonitorEvent.Action`
+)
+
+// String - stringer interface
+func (thisRef MonitorEventType) String() string {
+	switch thisRef {
+	case MonitorEventStarted:
+		return "started"
+	case MonitorEventExited:
+		return "exited"
+	case MonitorEventWatchdog:
+		return "watchdog"
+
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText - JSON as the string form
+func (thisRef MonitorEventType) MarshalText() ([]byte, error) {
+	return []byte(thisRef.String()), nil
+}
+
+// MonitorEvent - something that happened to a monitored process
+type MonitorEvent struct {
+	Type      MonitorEventType   `json:"type"`
+	Tag       string             `json:"tag"`
+	Time      time.Time          `json:"time"`
+	ProcessID int                `json:"processID"`
+	Result    *ProcessExitResult `json:"result,omitempty"`  // only for `MonitorEventExited`
+	Action    string             `json:"action,omitempty"`  // the `WatchdogAction*` taken, only for `MonitorEventWatchdog`
+	Message   string             `json:"message,omitempty"` // what was over the limit, only for `MonitorEventWatchdog`
+}
@@ -1,17 +1,46 @@
 package contracts
 
-import "time"
+import (
+	"context"
+	"syscall"
+	"time"
+)
+
+// MonitorBulkResult - what a bulk operation did to one tag
+type MonitorBulkResult struct {
+	Tag   string `json:"tag"`
+	Error string `json:"error,omitempty"`
+}
 
 // Monitor - process monitor
 type Monitor interface {
 	Spawn(process ProcessTemplate) (string, error)
 	SpawnWithTag(process ProcessTemplate, tag string) error
+	Schedule(process ProcessTemplate, tag string, schedule ProcessSchedule) error
+	SpawnPool(name string, process ProcessTemplate, replicas int) error
+	Scale(name string, replicas int) error
+	PoolStatus(name string) (ProcessPoolStatus, error)
 	Start(tag string) error
 	Stop(tag string) error
 	StopWithTimeout(tag string, attempts int, waitTimeout time.Duration) error
 	Restart(tag string) error
+	Signal(tag string, sig syscall.Signal) error
+	Pause(tag string) error
+	Resume(tag string) error
+	Reload(tag string) error
+	Output(tag string, lines int) []ProcessOutputLine
 	StopAllInParallel()
 	GetProcess(tag string) RuningProcess
 	RemoveFromMonitor(tag string)
 	GetAllTags() []string
+	Events(ctx context.Context) <-chan MonitorEvent
+	History(tag string) []ProcessExitResult
+
+	SetLabels(tag string, labels map[string]string) error
+	Labels(tag string) map[string]string
+	Select(selector string) ([]string, error)
+	StopSelected(selector string) ([]MonitorBulkResult, error)
+	RestartSelected(selector string) ([]MonitorBulkResult, error)
+	SignalSelected(selector string, sig syscall.Signal) ([]MonitorBulkResult, error)
+	RemoveSelected(selector string) ([]MonitorBulkResult, error)
 }
@@ -0,0 +1,65 @@
+package contracts
+
+// FileDescriptorType -
+type FileDescriptorType int
+
+// FileDescriptorTypeUnknown -
+const (
+	FileDescriptorTypeUnknown   FileDescriptorType = iota
+	FileDescriptorTypeFile                         // regular file or directory
+	FileDescriptorTypePipe                         // pipe or FIFO
+	FileDescriptorTypeSocket                       // any socket, see `OpenFile.Socket`
+	FileDescriptorTypeAnonInode                    // eventfd, epoll, timerfd, signalfd, pidfd, ...
+	FileDescriptorTypeDevice                       // character or block device
+)
+
+// String - stringer interface
+func (thisRef FileDescriptorType) String() string {
+	switch thisRef {
+	case FileDescriptorTypeFile:
+		return "file"
+	case FileDescriptorTypePipe:
+		return "pipe"
+	case FileDescriptorTypeSocket:
+		return "socket"
+	case FileDescriptorTypeAnonInode:
+		return "anon_inode"
+	case FileDescriptorTypeDevice:
+		return "device"
+
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText - JSON as the string form
+func (thisRef FileDescriptorType) MarshalText() ([]byte, error) {
+	return []byte(thisRef.String()), nil
+}
+
+// OpenFile - a file descriptor held by a process, like a line of `lsof -p`
+type OpenFile struct {
+	FD       int                `json:"fd"`
+	Type     FileDescriptorType `json:"type"`
+	Target   string             `json:"target"` // what the descriptor points to, a path or something like `socket:[1234]`
+	Inode    uint64             `json:"inode"`
+	Flags    int                `json:"flags"` // open(2) flags, like O_RDWR | O_APPEND
+	Position int64              `json:"position"`
+	Socket   *Socket            `json:"socket,omitempty"` // only for sockets found in the network tables
+}
+
+// Socket - a socket as listed in `/proc/net/{tcp,tcp6,udp,udp6,unix}`
+type Socket struct {
+	Protocol      string `json:"protocol"` // tcp, tcp6, udp, udp6 or unix
+	LocalAddress  string `json:"localAddress"`
+	LocalPort     int    `json:"localPort"`
+	RemoteAddress string `json:"remoteAddress"`
+	RemotePort    int    `json:"remotePort"`
+	State         string `json:"state"` // LISTEN, ESTABLISHED, ...
+	Inode         uint64 `json:"inode"`
+}
+
+// IsListening - `true` for TCP/unix sockets accepting connections and for bound unconnected UDP sockets
+func (thisRef Socket) IsListening() bool {
+	return thisRef.State == "LISTEN" || ((thisRef.Protocol == "udp" || thisRef.Protocol == "udp6") && thisRef.State == "UNCONNECTED")
+}
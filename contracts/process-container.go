@@ -0,0 +1,21 @@
+package contracts
+
+// ProcessNamespaces - inode numbers of the namespaces a process is in, from `/proc/<pid>/ns/*`,
+// two processes share a namespace when the numbers are equal, 0 means unknown
+type ProcessNamespaces struct {
+	Cgroup uint64 `json:"cgroup"`
+	IPC    uint64 `json:"ipc"`
+	Mount  uint64 `json:"mount"`
+	Net    uint64 `json:"net"`
+	PID    uint64 `json:"pid"`
+	Time   uint64 `json:"time"`
+	User   uint64 `json:"user"`
+	UTS    uint64 `json:"uts"`
+}
+
+// ProcessContainer - the container a process runs in, detected from its cgroup path
+type ProcessContainer struct {
+	CgroupPath string `json:"cgroupPath"`
+	ID         string `json:"id"`      // empty if the process doesn't look like it runs in a container
+	Runtime    string `json:"runtime"` // docker, containerd, podman, kubepods
+}
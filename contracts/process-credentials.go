@@ -0,0 +1,19 @@
+package contracts
+
+// ProcessCredentials - who a process runs as, `RuntimeProcess.UserID` and `RuntimeProcess.GroupID` are the real IDs,
+// names are empty when they can't be resolved
+type ProcessCredentials struct {
+	RealUserID            int    `json:"realUserID"`
+	EffectiveUserID       int    `json:"effectiveUserID"` // what permission checks use
+	SavedUserID           int    `json:"savedUserID"`
+	FilesystemUserID      int    `json:"filesystemUserID"` // Linux only, same as the effective ID elsewhere
+	RealGroupID           int    `json:"realGroupID"`
+	EffectiveGroupID      int    `json:"effectiveGroupID"`
+	SavedGroupID          int    `json:"savedGroupID"`
+	FilesystemGroupID     int    `json:"filesystemGroupID"`
+	SupplementaryGroupIDs []int  `json:"supplementaryGroupIDs"`
+	UserName              string `json:"userName"` // of the effective user, `DOMAIN\user` on Windows
+	RealUserName          string `json:"realUserName"`
+	GroupName             string `json:"groupName"` // of the effective group, the primary group on Windows
+	RealGroupName         string `json:"realGroupName"`
+}
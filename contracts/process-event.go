@@ -0,0 +1,53 @@
+package contracts
+
+import "time"
+
+// ProcessEventType -
+type ProcessEventType int
+
+// ProcessEventStarted -
+const (
+	ProcessEventStarted ProcessEventType = iota // a process appeared
+	ProcessEventExited                          // a process went away
+	ProcessEventChanged                         // a process is still there but something about it changed, see `ProcessEvent.Changes`
+)
+
+// String - stringer interface
+func (thisRef ProcessEventType) String() string {
+	switch thisRef {
+	case ProcessEventStarted:
+		return "started"
+	case ProcessEventExited:
+		return "exited"
+	case ProcessEventChanged:
+		return "changed"
+
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText - JSON as the string form
+func (thisRef ProcessEventType) MarshalText() ([]byte, error) {
+	return []byte(thisRef.String()), nil
+}
+
+// ProcessChangeState - the values of `ProcessEvent.Changes`
+const (
+	ProcessChangeState            = "state"
+	ProcessChangeCommandLine      = "commandLine"
+	ProcessChangeWorkingDirectory = "workingDirectory"
+	ProcessChangeExec             = "exec" // reported by the Linux proc connector even if the command line stayed the same
+	ProcessChangeUserID           = "userID"
+	ProcessChangeExecutableName   = "executableName"
+)
+
+// ProcessEvent - something that happened to a process, processes are told apart by `RuntimeProcess.Identity`
+// so a reused PID shows up as an exit followed by a start
+type ProcessEvent struct {
+	Type     ProcessEventType `json:"type"`
+	Time     time.Time        `json:"time"`
+	Process  RuntimeProcess   `json:"process"`  // the latest known details, the last ones seen for `ProcessEventExited`
+	Previous *RuntimeProcess  `json:"previous"` // the details before the change, only for `ProcessEventChanged`
+	Changes  []string         `json:"changes"`  // what changed, only for `ProcessEventChanged`
+}
@@ -0,0 +1,26 @@
+package contracts
+
+import "time"
+
+// ProcessExitReason - why a process exited
+const (
+	ExitReasonExited   = "exited"   // exited on its own
+	ExitReasonStopped  = "stopped"  // stopped with `Stop()`
+	ExitReasonTimeout  = "timeout"  // ran longer than `ProcessTemplate.MaxRuntime`
+	ExitReasonStalled  = "stalled"  // no output for `ProcessTemplate.NoOutputTimeout`
+	ExitReasonWatchdog = "watchdog" // over the limits of `ProcessTemplate.Watchdog`
+)
+
+// ProcessExitResult - how the last run of a process ended
+type ProcessExitResult struct {
+	ProcessID int       `json:"processID"`
+	ExitCode  int       `json:"exitCode"`
+	Reason    string    `json:"reason"` // one of the `ExitReason*`, empty while running
+	StartedAt time.Time `json:"startedAt"`
+	ExitedAt  time.Time `json:"exitedAt"`
+}
+
+// Duration - how long the run took
+func (thisRef ProcessExitResult) Duration() time.Duration {
+	return thisRef.ExitedAt.Sub(thisRef.StartedAt)
+}
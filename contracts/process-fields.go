@@ -0,0 +1,19 @@
+package contracts
+
+// ProcessFields - selects which parts of `RuntimeProcess` to read, on Linux each one is backed by
+// a separate file in `/proc/<pid>`, on other platforms everything is read at once anyway
+type ProcessFields uint32
+
+// ProcessFieldStatus - ordered from cheap to expensive to read
+const (
+	ProcessFieldStatus           ProcessFields = 1 << iota // name, state, parent, user and group IDs, memory, context switches
+	ProcessFieldStat                                       // start time, identity, CPU counters, virtual size
+	ProcessFieldCommandLine                                // executable and args
+	ProcessFieldWorkingDirectory                           // working directory
+	ProcessFieldIO                                         // IO counters
+	ProcessFieldNamespaces                                 // namespaces, cgroup and container
+	ProcessFieldEnvironment                                // environment, can be large
+
+	ProcessFieldsNone ProcessFields = 0
+	ProcessFieldsAll  ProcessFields = ProcessFieldStatus | ProcessFieldStat | ProcessFieldCommandLine | ProcessFieldWorkingDirectory | ProcessFieldIO | ProcessFieldNamespaces | ProcessFieldEnvironment
+)
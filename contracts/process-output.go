@@ -0,0 +1,16 @@
+package contracts
+
+import "time"
+
+// Output streams
+const (
+	OutputStreamStdout = "stdout"
+	OutputStreamStderr = "stderr"
+)
+
+// ProcessOutputLine - a line a process wrote, kept with `ProcessTemplate.OutputTailLines`
+type ProcessOutputLine struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"` // one of the `OutputStream*`
+	Text   string    `json:"text"`
+}
@@ -0,0 +1,22 @@
+package contracts
+
+import "errors"
+
+// ErrPoolDoesNotExist -
+var ErrPoolDoesNotExist = errors.New("ErrPoolDoesNotExist")
+
+// ProcessPoolInstance - one replica of a pool
+type ProcessPoolInstance struct {
+	Tag       string `json:"tag"`
+	Index     int    `json:"index"`
+	Running   bool   `json:"running"`
+	ProcessID int    `json:"processID"` // of the last start, 0 if it never started
+}
+
+// ProcessPoolStatus - the replicas of a pool, ordered by index
+type ProcessPoolStatus struct {
+	Name      string                `json:"name"`
+	Replicas  int                   `json:"replicas"`
+	Running   int                   `json:"running"`
+	Instances []ProcessPoolInstance `json:"instances"`
+}
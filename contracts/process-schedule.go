@@ -0,0 +1,25 @@
+package contracts
+
+import "time"
+
+// Overlap policies, for a run that is due while the previous one still runs
+const (
+	OverlapSkip  = "skip"  // the new run is skipped
+	OverlapQueue = "queue" // the new run starts once the previous one exits, runs due in the meantime are merged into it
+	OverlapKill  = "kill"  // the previous run is stopped first
+)
+
+// Missed run policies, for runs that were due while the monitor was not running
+const (
+	MissedRunsSkip    = "skip"     // they are dropped
+	MissedRunsRunOnce = "run-once" // one run starts right away
+)
+
+// ProcessSchedule - when the monitor starts a tag, instead of starting it right away
+type ProcessSchedule struct {
+	Spec       string        `json:"spec"`       // a 5 field cron expression, `@daily` like descriptors, `@every 5m` or `@at 03:15` for a time of day, in local time
+	Overlap    string        `json:"overlap"`    // one of the `Overlap*`, empty for skip
+	Jitter     time.Duration `json:"jitter"`     // each run is delayed by a random duration up to this
+	MissedRuns string        `json:"missedRuns"` // one of the `MissedRuns*`, empty for skip
+	LastRunAt  time.Time     `json:"lastRunAt"`  // when the tag last ran before the monitor started, for `MissedRuns`
+}
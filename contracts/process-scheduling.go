@@ -0,0 +1,44 @@
+package contracts
+
+// Scheduling policies
+const (
+	SchedulingPolicyOther = "SCHED_OTHER"
+	SchedulingPolicyFIFO  = "SCHED_FIFO"
+	SchedulingPolicyRR    = "SCHED_RR"
+	SchedulingPolicyBatch = "SCHED_BATCH"
+	SchedulingPolicyIdle  = "SCHED_IDLE"
+)
+
+// IO priority classes
+const (
+	IOPriorityClassNone       = "none"
+	IOPriorityClassRealtime   = "realtime"
+	IOPriorityClassBestEffort = "best-effort"
+	IOPriorityClassIdle       = "idle"
+)
+
+// ProcessScheduling - CPU, IO and OOM scheduling of a process, everything except `Nice` is Linux only,
+// in a template the zero values keep what the process inherits, `SetNice` and `SetOOMScoreAdj` ask for
+// a 0 explicitly, like a nice 0 child of a monitor running at nice 10. The IO priority is set by `IOClass`,
+// so `IOLevel` 0 is always explicit
+type ProcessScheduling struct {
+	Nice           int    `json:"nice"`           // -20 (favorable) to 19
+	SetNice        bool   `json:"setNice"`        // in a template, applies `Nice` even when it is 0
+	CPUAffinity    []int  `json:"cpuAffinity"`    // the CPUs the process may run on, empty for all
+	Policy         string `json:"policy"`         // one of the `SchedulingPolicy*`, empty keeps the current one
+	Priority       int    `json:"priority"`       // 1 to 99 for `SCHED_FIFO` and `SCHED_RR`, 0 for the rest
+	IOClass        string `json:"ioClass"`        // one of the `IOPriorityClass*`, empty keeps the current one
+	IOLevel        int    `json:"ioLevel"`        // 0 (highest) to 7, for the realtime and best-effort classes
+	OOMScoreAdj    int    `json:"oomScoreAdj"`    // -1000 (never killed) to 1000 (killed first)
+	SetOOMScoreAdj bool   `json:"setOOMScoreAdj"` // in a template, applies `OOMScoreAdj` even when it is 0
+}
+
+// AppliesNice - a template asks for `Nice`
+func (thisRef ProcessScheduling) AppliesNice() bool {
+	return thisRef.Nice != 0 || thisRef.SetNice
+}
+
+// AppliesOOMScoreAdj - a template asks for `OOMScoreAdj`
+func (thisRef ProcessScheduling) AppliesOOMScoreAdj() bool {
+	return thisRef.OOMScoreAdj != 0 || thisRef.SetOOMScoreAdj
+}
@@ -0,0 +1,20 @@
+package contracts
+
+// SeccompModeDisabled - the values of `ProcessSecurity.SeccompMode`
+const (
+	SeccompModeDisabled = "disabled"
+	SeccompModeStrict   = "strict"
+	SeccompModeFilter   = "filter"
+)
+
+// ProcessSecurity - Linux capability sets as names like `CAP_NET_BIND_SERVICE`, seccomp mode and no_new_privs,
+// from `/proc/<pid>/status`, empty on other platforms
+type ProcessSecurity struct {
+	InheritableCapabilities []string `json:"inheritableCapabilities"`
+	PermittedCapabilities   []string `json:"permittedCapabilities"`
+	EffectiveCapabilities   []string `json:"effectiveCapabilities"`
+	BoundingCapabilities    []string `json:"boundingCapabilities"`
+	AmbientCapabilities     []string `json:"ambientCapabilities"`
+	SeccompMode             string   `json:"seccompMode"`
+	NoNewPrivileges         bool     `json:"noNewPrivileges"`
+}
@@ -1,5 +1,10 @@
 package contracts
 
+import (
+	"syscall"
+	"time"
+)
+
 // ProcessOutputReader -
 type ProcessOutputReader func(params interface{}, outputData []byte)
 
@@ -18,4 +23,55 @@ type ProcessTemplate struct {
 	StderrReaderParams interface{}            `json:"-"`
 	OnStopped          ProcessStoppedDelegate `json:"-"`
 	OnStoppedParams    interface{}            `json:"-"`
+
+	// the signal `Reload()` sends, 0 for SIGHUP
+	ReloadSignal syscall.Signal `json:"reloadSignal"`
+
+	// key/value pairs the monitor selects tags by, like `customer=acme`
+	Labels map[string]string `json:"labels"`
+
+	// Unix only, a user name or ID to run as, with its primary and supplementary groups, empty keeps the current user
+	User string `json:"user"`
+
+	// Linux only, capabilities like `CAP_NET_BIND_SERVICE` the process keeps after `User` drops root
+	AmbientCapabilities []string `json:"ambientCapabilities"`
+
+	// Linux only, sets no_new_privs, so setuid binaries and file capabilities can't raise privileges
+	NoNewPrivileges bool `json:"noNewPrivileges"`
+
+	// Linux only, runs the process in new namespaces, `nil` for none
+	Sandbox *ProcessSandbox `json:"sandbox"`
+
+	// nice value, CPU affinity, scheduling policy, IO priority and OOM score, `nil` inherits them
+	Scheduling *ProcessScheduling `json:"scheduling"`
+
+	// the monitor stops the process once it runs longer than this, 0 for no limit
+	MaxRuntime time.Duration `json:"maxRuntime"`
+
+	// the monitor stops the process once it writes nothing to STDOUT or STDERR for this long, 0 for no limit
+	NoOutputTimeout time.Duration `json:"noOutputTimeout"`
+
+	// soft memory and CPU limits the monitor enforces, `nil` for none
+	Watchdog *ProcessWatchdog `json:"watchdog"`
+
+	// how many of the last lines of STDOUT and STDERR `Output()` keeps, 0 for none
+	OutputTailLines int `json:"outputTailLines"`
+}
+
+// ProcessSandbox - namespaces and filesystem isolation for a spawned process, the mounts and
+// namespaces other than user need root (CAP_SYS_ADMIN)
+type ProcessSandbox struct {
+	Cloneflags         uintptr            `json:"cloneflags"`         // any of CLONE_NEWNS, CLONE_NEWPID, CLONE_NEWNET, CLONE_NEWUTS, CLONE_NEWIPC, CLONE_NEWUSER
+	Chroot             string             `json:"chroot"`             // new root directory, empty keeps the current one
+	ReadOnlyBindMounts []string           `json:"readOnlyBindMounts"` // host paths the process sees read-only at the same path, inside `Chroot` if set
+	PrivateTmp         bool               `json:"privateTmp"`         // an empty tmpfs on /tmp, inside `Chroot` if set
+	UIDMappings        []ProcessIDMapping `json:"uidMappings"`        // with CLONE_NEWUSER
+	GIDMappings        []ProcessIDMapping `json:"gidMappings"`        // with CLONE_NEWUSER
+}
+
+// ProcessIDMapping - maps `Size` IDs starting at `HostID` to IDs starting at `ContainerID` in a user namespace
+type ProcessIDMapping struct {
+	ContainerID int `json:"containerID"`
+	HostID      int `json:"hostID"`
+	Size        int `json:"size"`
 }
@@ -0,0 +1,20 @@
+package contracts
+
+import "time"
+
+// Watchdog actions
+const (
+	WatchdogActionWarn    = "warn"    // only reports the event
+	WatchdogActionRestart = "restart" // stops and starts the process again
+	WatchdogActionStop    = "stop"    // stops the process
+)
+
+// ProcessWatchdog - soft memory and CPU limits the monitor checks while the process runs
+type ProcessWatchdog struct {
+	Interval               time.Duration `json:"interval"`               // between samples, 0 for 5s
+	MaxResidentSize        uint64        `json:"maxResidentSize"`        // in bytes, 0 for no limit
+	MaxResidentSizeSamples int           `json:"maxResidentSizeSamples"` // consecutive samples over `MaxResidentSize` before acting, 0 for 1
+	MaxCPUPercent          float64       `json:"maxCPUPercent"`          // one busy CPU is 100%, 0 for no limit
+	MaxCPUPercentWindow    time.Duration `json:"maxCPUPercentWindow"`    // how long CPU stays over `MaxCPUPercent` before acting, 0 for one sample
+	Action                 string        `json:"action"`                 // one of the `WatchdogAction*`, empty for warn
+}
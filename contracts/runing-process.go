@@ -2,12 +2,16 @@ package contracts
 
 import (
 	"errors"
+	"syscall"
 	"time"
 )
 
 // ErrProcessDoesNotExist -
 var ErrProcessDoesNotExist = errors.New("ErrProcessDoesNotExist")
 
+// ErrSignalNotSupported - the signal has no equivalent on this platform
+var ErrSignalNotSupported = errors.New("ErrSignalNotSupported")
+
 // ProcessState -
 type ProcessState int
 
@@ -62,11 +66,23 @@ type RuntimeProcess struct {
 	ParentProcessID  int          `json:"parentProcessID"`
 	UserID           int          `json:"userID"`
 	GroupID          int          `json:"groupID"`
+	SessionID        int          `json:"sessionID"`
+	ProcessGroupID   int          `json:"processGroupID"`
+	TTY              string       `json:"tty"` // controlling terminal like `/dev/pts/0`, empty if there is none
 	State            ProcessState `json:"state"`
+	StartTime        time.Time    `json:"startTime"`
+	Identity         string       `json:"identity"`      // boot ID + PID + start time, stable across snapshots and PID reuse
+	NamespacePIDs    []int        `json:"namespacePIDs"` // PID in each nested PID namespace, outermost first, the last one is what the process sees as its own PID
+
+	CPU        ProcessCPU        `json:"cpu"`
+	Memory     ProcessMemory     `json:"memory"`
+	IO         ProcessIO         `json:"io"`
+	Namespaces ProcessNamespaces `json:"namespaces"`
+	Container  ProcessContainer  `json:"container"`
 
-	// FIXME
-	sessionID       int `json:"-"`
-	effectiveUserID int `json:"-"`
+	Credentials ProcessCredentials `json:"credentials"`
+	Security    ProcessSecurity    `json:"security"`
+	Scheduling  ProcessScheduling  `json:"scheduling"`
 }
 
 // RuningProcess - represents a running process
@@ -75,8 +91,22 @@ type RuningProcess interface {
 	Stop(tag string, attempts int, waitTimeout time.Duration) error
 	IsRunning() bool
 	Details() RuntimeProcess
+	Exited() <-chan struct{}
+
+	Signal(sig syscall.Signal) error
+	Pause() error
+	Resume() error
+	Reload() error
+	Output(lines int) []ProcessOutputLine
+
+	SetNice(nice int) error
+	SetCPUAffinity(cpus []int) error
+	SetSchedulingPolicy(policy string, priority int) error
+	SetIOPriority(class string, level int) error
+	SetOOMScoreAdj(score int) error
 
 	ExitCode() int
+	ExitResult() ProcessExitResult
 	StartedAt() time.Time
 	StoppedAt() time.Time
 }
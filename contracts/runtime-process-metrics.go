@@ -0,0 +1,52 @@
+package contracts
+
+import "time"
+
+// ProcessCPU - CPU usage counters and scheduling info
+type ProcessCPU struct {
+	UserTicks                   uint64 `json:"userTicks"`      // time spent in user mode, in ticks
+	SystemTicks                 uint64 `json:"systemTicks"`    // time spent in kernel mode, in ticks
+	TicksPerSecond              uint64 `json:"ticksPerSecond"` // the unit of `UserTicks` and `SystemTicks`
+	Priority                    int    `json:"priority"`
+	Nice                        int    `json:"nice"`
+	NumThreads                  int    `json:"numThreads"`
+	VoluntaryContextSwitches    uint64 `json:"voluntaryContextSwitches"`
+	NonVoluntaryContextSwitches uint64 `json:"nonVoluntaryContextSwitches"`
+}
+
+// TotalTime - user + system CPU time
+func (thisRef ProcessCPU) TotalTime() time.Duration {
+	if thisRef.TicksPerSecond == 0 {
+		return 0
+	}
+
+	return time.Duration(thisRef.UserTicks+thisRef.SystemTicks) * time.Second / time.Duration(thisRef.TicksPerSecond)
+}
+
+// ProcessMemory - memory usage, in bytes
+type ProcessMemory struct {
+	VirtualSize      uint64 `json:"virtualSize"`
+	ResidentSize     uint64 `json:"residentSize"`
+	PeakResidentSize uint64 `json:"peakResidentSize"`
+	SwapSize         uint64 `json:"swapSize"`
+}
+
+// ProcessIO - IO counters
+//
+// `Available` is `false` when the platform has no such counters or they are not readable with
+// the current permissions (only the owner or root can read them on Linux), the counters are
+// meaningless in that case, they are not zero usage. `BlocksAvailable` covers the block counters
+// alone, BSD rusage only counts block operations so the byte counters stay unavailable there
+type ProcessIO struct {
+	Available           bool   `json:"available"`
+	BlocksAvailable     bool   `json:"blocksAvailable"`
+	ReadBytes           uint64 `json:"readBytes"`           // bytes fetched from the storage layer
+	WriteBytes          uint64 `json:"writeBytes"`          // bytes sent to the storage layer
+	CancelledWriteBytes uint64 `json:"cancelledWriteBytes"` // bytes written to page cache and then truncated before hitting storage
+	ReadChars           uint64 `json:"readChars"`           // bytes passed to read(), including from page cache, ttys and pipes
+	WriteChars          uint64 `json:"writeChars"`          // bytes passed to write()
+	ReadSyscalls        uint64 `json:"readSyscalls"`
+	WriteSyscalls       uint64 `json:"writeSyscalls"`
+	ReadBlocks          uint64 `json:"readBlocks"`  // block input operations, BSD rusage only
+	WriteBlocks         uint64 `json:"writeBlocks"` // block output operations, BSD rusage only
+}
@@ -0,0 +1,36 @@
+package contracts
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotAvailable - the information is not available on this platform or not readable with the current permissions
+var ErrNotAvailable = errors.New("ErrNotAvailable")
+
+// ProcessSample - resource usage of a process over the last sampling interval
+type ProcessSample struct {
+	Process             RuntimeProcess `json:"process"`
+	CPUPercent          float64        `json:"cpuPercent"` // 100 means one CPU fully used, same as `top`
+	MemoryPercent       float64        `json:"memoryPercent"`
+	ReadBytesPerSecond  float64        `json:"readBytesPerSecond"`
+	WriteBytesPerSecond float64        `json:"writeBytesPerSecond"`
+}
+
+// SampleSortOrder -
+type SampleSortOrder int
+
+// SortByCPU -
+const (
+	SortByCPU    SampleSortOrder = iota // highest CPU% first
+	SortByMemory                        // highest memory% first
+	SortByIO                            // highest read + write rate first
+	SortByPID                           // lowest PID first
+)
+
+// Sampler - periodically samples processes and computes usage rates
+type Sampler interface {
+	Sample() ([]ProcessSample, error)
+	Start(interval time.Duration) <-chan []ProcessSample
+	Stop()
+}
@@ -1,16 +1,52 @@
-package find
-
-import (
-	"github.com/remoteit/systemkit-processes/contracts"
-	"github.com/remoteit/systemkit-processes/internal"
-)
-
-// ProcessByPID - finds process by PID
-func ProcessByPID(pid int) (contracts.RuningProcess, error) {
-	return internal.GetRuningProcessByPID(pid)
-}
-
-// AllProcesses - returns all processes
-func AllProcesses() ([]contracts.RuningProcess, error) {
-	return internal.GetAllRuningProcesses()
-}
+package find
+
+import (
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// ProcessByPID - finds process by PID
+func ProcessByPID(pid int) (contracts.RuningProcess, error) {
+	return internal.GetRuningProcessByPID(pid)
+}
+
+// AllProcesses - returns all processes
+func AllProcesses() ([]contracts.RuningProcess, error) {
+	return internal.GetAllRuningProcesses()
+}
+
+// OpenFiles - lists the file descriptors held by a process, like `lsof -p`
+func OpenFiles(pid int) ([]contracts.OpenFile, error) {
+	return internal.GetOpenFiles(pid)
+}
+
+// ListeningPorts - the TCP and UDP sockets a process is listening on
+func ListeningPorts(pid int) ([]contracts.Socket, error) {
+	return internal.GetListeningPorts(pid)
+}
+
+// ProcessesByPort - finds the processes listening on `port`, `protocol` is one of tcp, tcp6, udp, udp6;
+// on Linux it looks in every network namespace the caller can inspect
+func ProcessesByPort(protocol string, port int) ([]contracts.RuningProcess, error) {
+	pids, err := internal.GetProcessIDsByPort(protocol, port)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []contracts.RuningProcess{}
+	for _, pid := range pids {
+		rp, err := internal.GetRuningProcessByPID(pid)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, rp)
+	}
+
+	return results, nil
+}
+
+// ContainerFromCgroup - detects the container from the content of a `/proc/<pid>/cgroup` file
+func ContainerFromCgroup(data string) contracts.ProcessContainer {
+	return internal.ContainerFromProcCgroup(data)
+}
@@ -0,0 +1,442 @@
+package find
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// QuerySortOrder -
+type QuerySortOrder int
+
+// SortByPID -
+const (
+	SortByPID    QuerySortOrder = iota // lowest PID first
+	SortByNewest                       // most recently started first
+	SortByOldest                       // least recently started first
+	SortByName                         // executable name, then PID
+)
+
+// Query - a composable process filter, predicates are evaluated from the cheapest to read to the
+// most expensive one, so a process that fails on its name never gets its environment read
+type Query struct {
+	predicates []queryPredicate
+	fields     contracts.ProcessFields
+	limit      int
+	sortOrder  QuerySortOrder
+	err        error
+}
+
+type queryPredicate struct {
+	fields contracts.ProcessFields
+	match  func(rp contracts.RuntimeProcess) bool
+}
+
+// NewQuery - a query that matches all processes
+func NewQuery() *Query {
+	return &Query{
+		predicates: []queryPredicate{},
+		fields:     contracts.ProcessFieldsAll,
+		limit:      0,
+		sortOrder:  SortByPID,
+		err:        nil,
+	}
+}
+
+// Where - adds a custom predicate, `fields` must cover everything `match` looks at
+func (thisRef *Query) Where(fields contracts.ProcessFields, match func(rp contracts.RuntimeProcess) bool) *Query {
+	thisRef.predicates = append(thisRef.predicates, queryPredicate{
+		fields: fields,
+		match:  match,
+	})
+
+	return thisRef
+}
+
+// ExecutableName - exact match on the executable name, on Linux the kernel truncates it to 15 characters
+func (thisRef *Query) ExecutableName(name string) *Query {
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		return rp.ExecutableName == name
+	})
+}
+
+// ExecutableNameGlob - shell pattern match on the executable name, like `sh*`
+func (thisRef *Query) ExecutableNameGlob(pattern string) *Query {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		thisRef.setError(fmt.Errorf("bad glob [%s], %s", pattern, err.Error()))
+	}
+
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		matched, _ := filepath.Match(pattern, rp.ExecutableName)
+		return matched
+	})
+}
+
+// ExecutableNameRegex - regular expression match on the executable name
+func (thisRef *Query) ExecutableNameRegex(expr string) *Query {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		thisRef.setError(err)
+		return thisRef
+	}
+
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		return re.MatchString(rp.ExecutableName)
+	})
+}
+
+// CommandLineContains - substring match on the executable and args joined by spaces
+func (thisRef *Query) CommandLineContains(substr string) *Query {
+	return thisRef.Where(contracts.ProcessFieldCommandLine, func(rp contracts.RuntimeProcess) bool {
+		return strings.Contains(CommandLine(rp), substr)
+	})
+}
+
+// CommandLineRegex - regular expression match on the executable and args joined by spaces
+func (thisRef *Query) CommandLineRegex(expr string) *Query {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		thisRef.setError(err)
+		return thisRef
+	}
+
+	return thisRef.Where(contracts.ProcessFieldCommandLine, func(rp contracts.RuntimeProcess) bool {
+		return re.MatchString(CommandLine(rp))
+	})
+}
+
+// UserID - processes running as `uid`
+func (thisRef *Query) UserID(uid int) *Query {
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		return rp.UserID == uid
+	})
+}
+
+// UserName - processes running as user `name`
+func (thisRef *Query) UserName(name string) *Query {
+	u, err := user.Lookup(name)
+	if err != nil {
+		thisRef.setError(err)
+		return thisRef
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		thisRef.setError(fmt.Errorf("user [%s] has a non-numeric UID [%s]", name, u.Uid))
+		return thisRef
+	}
+
+	return thisRef.UserID(uid)
+}
+
+// ParentProcessID - direct children of `ppid`
+func (thisRef *Query) ParentProcessID(ppid int) *Query {
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		return rp.ParentProcessID == ppid
+	})
+}
+
+// SessionID - processes in session `sid`
+func (thisRef *Query) SessionID(sid int) *Query {
+	return thisRef.Where(contracts.ProcessFieldStat, func(rp contracts.RuntimeProcess) bool {
+		return rp.SessionID == sid
+	})
+}
+
+// ExcludeProcessIDs - drops processes with any of `pids`
+func (thisRef *Query) ExcludeProcessIDs(pids ...int) *Query {
+	excluded := map[int]bool{}
+	for _, pid := range pids {
+		excluded[pid] = true
+	}
+
+	return thisRef.Where(contracts.ProcessFieldsNone, func(rp contracts.RuntimeProcess) bool {
+		return !excluded[rp.ProcessID]
+	})
+}
+
+// SamePIDNamespace - processes in the PID namespace of the current process, inside a container that
+// leaves out what the host shares with it, fails with `contracts.ErrNotAvailable` where namespaces don't exist
+//
+// The `ns/pid` link of another user's process needs ptrace access, without it the depth of `NSpid` in the
+// world readable `status` decides, a process visible in `/proc` is in its namespace or a nested one, so the
+// same depth means the same namespace
+func (thisRef *Query) SamePIDNamespace() *Query {
+	self := contracts.RuntimeProcess{}
+	_, err := internal.ReadRuntimeProcessFields(os.Getpid(), &self, contracts.ProcessFieldStatus|contracts.ProcessFieldNamespaces)
+	if err == nil && self.Namespaces.PID == 0 {
+		err = contracts.ErrNotAvailable
+	}
+	if err != nil {
+		thisRef.setError(err)
+		return thisRef
+	}
+
+	return thisRef.Where(contracts.ProcessFieldStatus|contracts.ProcessFieldNamespaces, func(rp contracts.RuntimeProcess) bool {
+		if rp.Namespaces.PID != 0 {
+			return rp.Namespaces.PID == self.Namespaces.PID
+		}
+
+		return len(rp.NamespacePIDs) > 0 && len(rp.NamespacePIDs) == len(self.NamespacePIDs)
+	})
+}
+
+// ContainerID - processes running in the container with `id`, a prefix like the short ID `docker ps` shows works too
+func (thisRef *Query) ContainerID(id string) *Query {
+	return thisRef.Where(contracts.ProcessFieldNamespaces, func(rp contracts.RuntimeProcess) bool {
+		return len(id) > 0 && strings.HasPrefix(rp.Container.ID, id)
+	})
+}
+
+// WorkingDirectory - processes whose working directory is `dir`
+func (thisRef *Query) WorkingDirectory(dir string) *Query {
+	dir = filepath.Clean(dir)
+
+	return thisRef.Where(contracts.ProcessFieldWorkingDirectory, func(rp contracts.RuntimeProcess) bool {
+		return rp.WorkingDirectory != "" && filepath.Clean(rp.WorkingDirectory) == dir
+	})
+}
+
+// Environment - processes that have `key` set to `value` in their environment
+func (thisRef *Query) Environment(key string, value string) *Query {
+	entry := key + "=" + value
+
+	return thisRef.Where(contracts.ProcessFieldEnvironment, func(rp contracts.RuntimeProcess) bool {
+		for _, env := range rp.Environment {
+			if env == entry {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// EnvironmentKey - processes that have `key` set in their environment, to any value
+func (thisRef *Query) EnvironmentKey(key string) *Query {
+	prefix := key + "="
+
+	return thisRef.Where(contracts.ProcessFieldEnvironment, func(rp contracts.RuntimeProcess) bool {
+		for _, env := range rp.Environment {
+			if strings.HasPrefix(env, prefix) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// State - processes in any of `states`
+func (thisRef *Query) State(states ...contracts.ProcessState) *Query {
+	return thisRef.Where(contracts.ProcessFieldStatus, func(rp contracts.RuntimeProcess) bool {
+		for _, state := range states {
+			if rp.State == state {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// OlderThan - processes started more than `age` ago
+func (thisRef *Query) OlderThan(age time.Duration) *Query {
+	return thisRef.Where(contracts.ProcessFieldStat, func(rp contracts.RuntimeProcess) bool {
+		return time.Since(rp.StartTime) > age
+	})
+}
+
+// NewerThan - processes started less than `age` ago
+func (thisRef *Query) NewerThan(age time.Duration) *Query {
+	return thisRef.Where(contracts.ProcessFieldStat, func(rp contracts.RuntimeProcess) bool {
+		return time.Since(rp.StartTime) < age
+	})
+}
+
+// Fields - the fields to read for the matches, defaults to all
+func (thisRef *Query) Fields(fields contracts.ProcessFields) *Query {
+	thisRef.fields = fields
+	return thisRef
+}
+
+// Limit - return at most `limit` matches, 0 means no limit
+func (thisRef *Query) Limit(limit int) *Query {
+	thisRef.limit = limit
+	return thisRef
+}
+
+// SortBy - the order of the matches, the limit applies after sorting
+func (thisRef *Query) SortBy(sortOrder QuerySortOrder) *Query {
+	thisRef.sortOrder = sortOrder
+	return thisRef
+}
+
+// Run - returns the matching processes
+func (thisRef *Query) Run() ([]contracts.RuningProcess, error) {
+	rps, err := thisRef.RunDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]contracts.RuningProcess, 0, len(rps))
+	for _, rp := range rps {
+		runingProcess, err := internal.NewRuningProcessFromRuntimeProcess(rp)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, runingProcess)
+	}
+
+	return results, nil
+}
+
+// RunDetails - returns details of the matching processes, only the fields selected with `Fields()`
+// and the ones the predicates needed are read
+func (thisRef *Query) RunDetails() ([]contracts.RuntimeProcess, error) {
+	if thisRef.err != nil {
+		return nil, thisRef.err
+	}
+
+	pids, err := internal.ListProcessIDs()
+	if err != nil {
+		return nil, err
+	}
+	sort.Ints(pids)
+
+	predicates := thisRef.sortedPredicates()
+
+	sortFields := contracts.ProcessFieldsNone
+	switch thisRef.sortOrder {
+	case SortByNewest, SortByOldest:
+		sortFields = contracts.ProcessFieldStat
+	case SortByName:
+		sortFields = contracts.ProcessFieldStatus
+	}
+
+	canStopEarly := thisRef.limit > 0 && thisRef.sortOrder == SortByPID
+
+	results := []contracts.RuntimeProcess{}
+	for _, pid := range pids {
+		rp, ok := readMatchingProcess(pid, predicates, thisRef.fields|sortFields)
+		if !ok {
+			continue
+		}
+
+		results = append(results, rp)
+
+		if canStopEarly && len(results) >= thisRef.limit {
+			break
+		}
+	}
+
+	sortRuntimeProcesses(results, thisRef.sortOrder)
+
+	if thisRef.limit > 0 && len(results) > thisRef.limit {
+		results = results[:thisRef.limit]
+	}
+
+	return results, nil
+}
+
+// Match - reads the details of `pid` and checks them against the query, returns them with the fields
+// selected with `Fields()` if they match
+func (thisRef *Query) Match(pid int) (contracts.RuntimeProcess, bool) {
+	if thisRef.err != nil {
+		return contracts.RuntimeProcess{}, false
+	}
+
+	return readMatchingProcess(pid, thisRef.sortedPredicates(), thisRef.fields)
+}
+
+// sortedPredicates - cheapest first
+func (thisRef *Query) sortedPredicates() []queryPredicate {
+	predicates := make([]queryPredicate, len(thisRef.predicates))
+	copy(predicates, thisRef.predicates)
+	sort.SliceStable(predicates, func(i, j int) bool {
+		return predicates[i].fields < predicates[j].fields
+	})
+
+	return predicates
+}
+
+// readMatchingProcess - reads only what the next predicate needs and stops at the first one that fails
+func readMatchingProcess(pid int, predicates []queryPredicate, fields contracts.ProcessFields) (contracts.RuntimeProcess, bool) {
+	rp := contracts.RuntimeProcess{ProcessID: pid}
+	loaded := contracts.ProcessFieldsNone
+
+	var err error
+	for _, predicate := range predicates {
+		if loaded, err = loadFields(pid, &rp, loaded, predicate.fields); err != nil || !predicate.match(rp) {
+			return rp, false
+		}
+	}
+
+	if _, err = loadFields(pid, &rp, loaded, fields); err != nil {
+		return rp, false
+	}
+
+	return rp, true
+}
+
+// clone - a copy that can get more predicates without changing the original
+func (thisRef *Query) clone() *Query {
+	c := *thisRef
+	c.predicates = make([]queryPredicate, len(thisRef.predicates))
+	copy(c.predicates, thisRef.predicates)
+
+	return &c
+}
+
+func (thisRef *Query) setError(err error) {
+	if thisRef.err == nil {
+		thisRef.err = err
+	}
+}
+
+// loadFields - reads what is in `fields` and not yet in `loaded`
+func loadFields(pid int, rp *contracts.RuntimeProcess, loaded contracts.ProcessFields, fields contracts.ProcessFields) (contracts.ProcessFields, error) {
+	missing := fields &^ loaded
+	if missing == contracts.ProcessFieldsNone {
+		return loaded, nil
+	}
+
+	read, err := internal.ReadRuntimeProcessFields(pid, rp, missing)
+	if err != nil {
+		return loaded, err
+	}
+
+	return loaded | read, nil
+}
+
+func sortRuntimeProcesses(rps []contracts.RuntimeProcess, sortOrder QuerySortOrder) {
+	sort.SliceStable(rps, func(i, j int) bool {
+		switch sortOrder {
+		case SortByNewest:
+			return rps[i].StartTime.After(rps[j].StartTime)
+		case SortByOldest:
+			return rps[i].StartTime.Before(rps[j].StartTime)
+		case SortByName:
+			if rps[i].ExecutableName != rps[j].ExecutableName {
+				return rps[i].ExecutableName < rps[j].ExecutableName
+			}
+		}
+
+		return rps[i].ProcessID < rps[j].ProcessID
+	})
+}
+
+// CommandLine - the executable and args joined by spaces
+func CommandLine(rp contracts.RuntimeProcess) string {
+	return strings.TrimSpace(rp.Executable + " " + strings.Join(rp.Args, " "))
+}
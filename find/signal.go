@@ -0,0 +1,109 @@
+package find
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// SignalOptions - `pkill` style options for `Signal()`
+type SignalOptions struct {
+	Newest      bool          // only the most recently started match, like `pkill -n`
+	Oldest      bool          // only the least recently started match, like `pkill -o`
+	IncludeSelf bool          // by default the calling process and its ancestors are never matched
+	StopLadder  bool          // escalate SIGINT -> SIGTERM -> SIGKILL like `RuningProcess.Stop()` instead of sending the signal
+	Attempts    int           // attempts per step of the stop ladder, defaults to 3
+	WaitTimeout time.Duration // wait between attempts of the stop ladder
+	DryRun      bool          // match and report, signal nothing
+}
+
+// SignalResult - what happened to one matched process
+type SignalResult struct {
+	ProcessID      int    `json:"processID"`
+	ExecutableName string `json:"executableName"`
+	CommandLine    string `json:"commandLine"`
+	Signalled      bool   `json:"signalled"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Signal - sends `signal` to every process matching `query`, like `pkill`, and reports per PID
+func Signal(query *Query, signal syscall.Signal, opts SignalOptions) ([]SignalResult, error) {
+	q := query.clone()
+
+	if !opts.IncludeSelf {
+		q.ExcludeProcessIDs(selfAndAncestors()...)
+	}
+
+	if opts.Newest {
+		q.SortBy(SortByNewest).Limit(1)
+	} else if opts.Oldest {
+		q.SortBy(SortByOldest).Limit(1)
+	}
+
+	rps, err := q.Fields(contracts.ProcessFieldStatus | contracts.ProcessFieldStat | contracts.ProcessFieldCommandLine).RunDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+
+	results := make([]SignalResult, 0, len(rps))
+	for _, rp := range rps {
+		result := SignalResult{
+			ProcessID:      rp.ProcessID,
+			ExecutableName: rp.ExecutableName,
+			CommandLine:    CommandLine(rp),
+		}
+
+		if !opts.DryRun {
+			err = signalOne(rp, signal, opts.StopLadder, attempts, opts.WaitTimeout)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Signalled = true
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func signalOne(rp contracts.RuntimeProcess, signal syscall.Signal, stopLadder bool, attempts int, waitTimeout time.Duration) error {
+	runingProcess, err := internal.NewRuningProcessFromRuntimeProcess(rp)
+	if err != nil {
+		return err
+	}
+
+	if stopLadder {
+		return runingProcess.Stop("", attempts, waitTimeout)
+	}
+
+	return runingProcess.Signal(signal)
+}
+
+// selfAndAncestors - this process and all its parents up to PID 1
+func selfAndAncestors() []int {
+	pids := []int{}
+
+	pid := os.Getpid()
+	for pid > 0 && len(pids) < 1024 {
+		pids = append(pids, pid)
+
+		rp := contracts.RuntimeProcess{}
+		if _, err := internal.ReadRuntimeProcessFields(pid, &rp, contracts.ProcessFieldStatus); err != nil {
+			break
+		}
+
+		pid = rp.ParentProcessID
+	}
+
+	return pids
+}
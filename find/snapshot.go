@@ -0,0 +1,75 @@
+package find
+
+import (
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// Snapshot - details of all processes at one point in time, with only the requested fields read,
+// more fields can be read later with `Load()`
+type Snapshot struct {
+	processes []contracts.RuntimeProcess
+	loaded    []contracts.ProcessFields
+	workers   int
+}
+
+// NewSnapshot - reads `fields` of all processes in parallel, `contracts.ProcessFieldStatus` is always read
+func NewSnapshot(fields contracts.ProcessFields) (*Snapshot, error) {
+	return NewSnapshotWithWorkers(fields, internal.DefaultSnapshotWorkers())
+}
+
+// NewSnapshotWithWorkers - same as `NewSnapshot()` with at most `workers` processes read at the same time
+func NewSnapshotWithWorkers(fields contracts.ProcessFields, workers int) (*Snapshot, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	rps, loaded, err := internal.SnapshotRuntimeProcesses(fields, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		processes: rps,
+		loaded:    loaded,
+		workers:   workers,
+	}, nil
+}
+
+// Processes - the processes in the snapshot, ordered by PID
+func (thisRef *Snapshot) Processes() []contracts.RuntimeProcess {
+	return thisRef.processes
+}
+
+// Fields - the fields read for every process in the snapshot
+func (thisRef *Snapshot) Fields() contracts.ProcessFields {
+	if len(thisRef.loaded) == 0 {
+		return contracts.ProcessFieldsNone
+	}
+
+	fields := contracts.ProcessFieldsAll
+	for _, loaded := range thisRef.loaded {
+		fields &= loaded
+	}
+
+	return fields
+}
+
+// Load - reads `fields` not read yet for every process in the snapshot, processes that exited since are dropped
+func (thisRef *Snapshot) Load(fields contracts.ProcessFields) {
+	internal.ReadRuntimeProcessesFields(thisRef.processes, thisRef.loaded, fields, thisRef.workers)
+
+	processes := thisRef.processes[:0]
+	loaded := thisRef.loaded[:0]
+	for i := range thisRef.processes {
+		if thisRef.processes[i].State == contracts.ProcessStateNonExistent {
+			continue
+		}
+
+		processes = append(processes, thisRef.processes[i])
+		loaded = append(loaded, thisRef.loaded[i])
+	}
+
+	thisRef.processes = processes
+	thisRef.loaded = loaded
+}
@@ -0,0 +1,34 @@
+// +build linux
+
+package tests
+
+import (
+	"io/ioutil"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+// TestAllProcessesHoldNoDescriptors - checking every process must not keep a pidfd per process open
+func TestAllProcessesHoldNoDescriptors(t *testing.T) {
+	before, _ := ioutil.ReadDir("/proc/self/fd")
+
+	allProcesses, err := find.AllProcesses()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, proc := range allProcesses {
+		proc.IsRunning()
+		proc.Signal(syscall.Signal(0))
+	}
+
+	after, _ := ioutil.ReadDir("/proc/self/fd")
+	runtime.KeepAlive(allProcesses)
+
+	if len(after) > len(before)+2 {
+		t.Fatalf("bad: %d descriptors before, %d after checking %d processes", len(before), len(after), len(allProcesses))
+	}
+}
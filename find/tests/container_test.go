@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestContainerFromCgroup(t *testing.T) {
+	id := "4f1a6d5c9e0b8a7f3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b"
+
+	tests := []struct {
+		cgroup  string
+		runtime string
+		id      string
+	}{
+		{"12:memory:/docker/" + id + "\n0::/", "docker", id},
+		{"0::/system.slice/docker-" + id + ".scope\n", "docker", id},
+		{"0::/system.slice/containerd.service/cri-containerd-" + id + ".scope\n", "containerd", id},
+		{"0::/machine.slice/libpod-" + id + ".scope/container\n", "podman", id},
+		{"11:cpu:/kubepods/burstable/pod7e2b1c3d-0000-4000-8000-000000000000/" + id + "\n", "kubepods", id},
+		{"0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1.slice/cri-containerd-" + id + ".scope\n", "kubepods", id},
+		{"4:memory:/user.slice\n0::/user.slice/user-1000.slice/session-2.scope\n", "", ""},
+	}
+
+	for _, test := range tests {
+		container := find.ContainerFromCgroup(test.cgroup)
+		if container.Runtime != test.runtime || container.ID != test.id {
+			t.Fatalf("bad: %#v for %q", container, test.cgroup)
+		}
+	}
+
+	container := find.ContainerFromCgroup("4:memory:/user.slice\n0::/user.slice/session-2.scope\n")
+	if container.CgroupPath != "/user.slice/session-2.scope" {
+		t.Fatalf("bad: %#v", container)
+	}
+}
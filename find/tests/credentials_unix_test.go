@@ -0,0 +1,38 @@
+// +build !windows
+
+package tests
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/find"
+	"golang.org/x/sys/unix"
+)
+
+func TestCredentials(t *testing.T) {
+	rp, err := find.ProcessByPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	details := rp.Details()
+	credentials := details.Credentials
+
+	if credentials.RealUserID != os.Getuid() || credentials.EffectiveUserID != os.Geteuid() ||
+		credentials.RealGroupID != os.Getgid() || credentials.EffectiveGroupID != os.Getegid() {
+		t.Fatalf("bad: %#v", credentials)
+	}
+
+	pgid, _ := unix.Getpgid(0)
+	if details.ProcessGroupID != pgid {
+		t.Fatalf("bad: %d, expected %d", details.ProcessGroupID, pgid)
+	}
+
+	u, err := user.LookupId(strconv.Itoa(os.Geteuid()))
+	if err == nil && credentials.UserName != u.Username {
+		t.Fatalf("bad: %s, expected %s", credentials.UserName, u.Username)
+	}
+}
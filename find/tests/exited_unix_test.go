@@ -0,0 +1,38 @@
+// +build !windows
+
+package tests
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestExitedNonChild(t *testing.T) {
+	cmd := exec.Command("sleep", "1")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	go cmd.Wait()
+
+	rp, err := find.ProcessByPID(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !rp.IsRunning() {
+		t.Fatal("should be running")
+	}
+
+	select {
+	case <-rp.Exited():
+	case <-time.After(10 * time.Second):
+		t.Fatal("should have exited")
+	}
+
+	if rp.IsRunning() {
+		t.Fatal("should not be running")
+	}
+}
@@ -0,0 +1,103 @@
+// +build linux
+
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestNamespaces(t *testing.T) {
+	rps, err := find.NewQuery().
+		SamePIDNamespace().
+		Fields(contracts.ProcessFieldStatus | contracts.ProcessFieldNamespaces).
+		RunDetails()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var self *contracts.RuntimeProcess
+	for i := range rps {
+		if rps[i].ProcessID == os.Getpid() {
+			self = &rps[i]
+		}
+	}
+
+	if self == nil {
+		t.Fatal("own process is missing")
+	}
+
+	if self.Namespaces.PID == 0 || self.Namespaces.Mount == 0 ||
+		len(self.NamespacePIDs) == 0 || self.NamespacePIDs[len(self.NamespacePIDs)-1] != os.Getpid() {
+		t.Fatalf("bad: %#v, %#v", self.Namespaces, self.NamespacePIDs)
+	}
+}
+
+// TestSamePIDNamespaceOtherUsers - the `ns/pid` link of another user's process can't be read without
+// ptrace access, those processes are still in the namespace
+func TestSamePIDNamespaceOtherUsers(t *testing.T) {
+	if os.Getenv("SAME_PID_NAMESPACE_HELPER") == "1" {
+		samePIDNamespaceOtherUsers(t)
+		return
+	}
+
+	if os.Getuid() != 0 {
+		samePIDNamespaceOtherUsers(t)
+		return
+	}
+
+	// root reads every link, so the check runs as `nobody` from a copy of the test binary it can execute
+	folder, err := ioutil.TempDir("", "same-pid-namespace")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(folder)
+
+	binary := filepath.Join(folder, "tests.test")
+	data, err := ioutil.ReadFile(os.Args[0])
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(binary, data, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	os.Chmod(folder, 0755)
+
+	cmd := exec.Command(binary, "-test.run=^TestSamePIDNamespaceOtherUsers$", "-test.v")
+	cmd.Dir = folder
+	cmd.Env = append(os.Environ(), "SAME_PID_NAMESPACE_HELPER=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: 65534, Gid: 65534}}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "--- PASS") {
+		t.Fatalf("err: %v, %s", err, output)
+	}
+}
+
+func samePIDNamespaceOtherUsers(t *testing.T) {
+	if _, err := os.Readlink("/proc/1/ns/pid"); err == nil {
+		t.Skip("the namespaces of PID 1 are readable")
+	}
+
+	// PID 1 of `/proc` is always in the namespace `/proc` shows
+	rps, err := find.NewQuery().SamePIDNamespace().RunDetails()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, rp := range rps {
+		if rp.ProcessID == 1 {
+			return
+		}
+	}
+
+	t.Fatal("PID 1 is missing")
+}
@@ -0,0 +1,71 @@
+// +build linux
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestOpenFiles(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	openFiles, err := find.OpenFiles(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	found := false
+	for _, openFile := range openFiles {
+		if openFile.Type == contracts.FileDescriptorTypeSocket && openFile.Socket != nil &&
+			openFile.Socket.LocalPort == port &&
+			openFile.Socket.LocalAddress == "127.0.0.1" &&
+			openFile.Socket.State == "LISTEN" {
+			found = true
+		}
+	}
+
+	if !found {
+		detailsAsBytes, _ := json.MarshalIndent(openFiles, "", "\t")
+		fmt.Println(string(detailsAsBytes))
+		t.Fatalf("should have found the listener on port %d", port)
+	}
+}
+
+func TestOpenFilesIPv6(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 is not available")
+	}
+	defer listener.Close()
+
+	address := listener.Addr().(*net.TCPAddr)
+
+	openFiles, err := find.OpenFiles(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, openFile := range openFiles {
+		if openFile.Socket != nil && openFile.Socket.LocalPort == address.Port {
+			if !net.ParseIP(openFile.Socket.LocalAddress).Equal(address.IP) {
+				t.Fatalf("bad: %s, expected %s", openFile.Socket.LocalAddress, address.IP)
+			}
+			return
+		}
+	}
+
+	t.Fatalf("should have found the listener on port %d", address.Port)
+}
@@ -0,0 +1,99 @@
+// +build linux
+
+package tests
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestListeningPorts(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	sockets, err := find.ListeningPorts(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	found := false
+	for _, socket := range sockets {
+		if socket.Protocol == "udp" && socket.LocalPort == port {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("should have found UDP port %d in %#v", port, sockets)
+	}
+
+	rps, err := find.ProcessesByPort("udp", port)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(rps) != 1 || rps[0].Details().ProcessID != os.Getpid() {
+		t.Fatalf("bad: %#v", rps)
+	}
+}
+
+func TestProcessesByPortInOtherNetworkNamespace(t *testing.T) {
+	if port := os.Getenv("PORTS_NETNS_HELPER"); port != "" {
+		conn, err := net.ListenPacket("udp", "0.0.0.0:"+port)
+		if err != nil {
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		os.Stdout.WriteString("listening\n")
+		os.Stdin.Read(make([]byte, 1))
+		return
+	}
+
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to create namespaces")
+	}
+
+	// a port free here, the helper's namespace has nothing else
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestProcessesByPortInOtherNetworkNamespace$")
+	cmd.Env = append(os.Environ(), "PORTS_NETNS_HELPER="+strconv.Itoa(port))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNET}
+	stdin, _ := cmd.StdinPipe()
+	stdout, _ := cmd.StdoutPipe()
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't create a network namespace: %s", err)
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+
+	if line, _ := bufio.NewReader(stdout).ReadString('\n'); line != "listening\n" {
+		t.Fatalf("helper failed to listen on %d", port)
+	}
+
+	rps, err := find.ProcessesByPort("udp", port)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(rps) != 1 || rps[0].Details().ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", rps)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/remoteit/systemkit-processes/find"
 )
@@ -23,3 +24,25 @@ func TestProcessByPID(t *testing.T) {
 	detailsAsBytes, _ := json.MarshalIndent(runtimeProcess, "", "\t")
 	fmt.Println(string(detailsAsBytes))
 }
+
+func TestProcessIdentity(t *testing.T) {
+	rp1, err := find.ProcessByPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	rp2, err := find.ProcessByPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	details1 := rp1.Details()
+	details2 := rp2.Details()
+
+	if details1.Identity == "" || details1.Identity != details2.Identity {
+		t.Fatalf("bad: %#v vs %#v", details1.Identity, details2.Identity)
+	}
+
+	if details1.StartTime.After(time.Now()) || time.Since(details1.StartTime) > time.Hour {
+		t.Fatalf("bad: %v", details1.StartTime)
+	}
+}
@@ -0,0 +1,41 @@
+// +build linux
+
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestProcessMetrics(t *testing.T) {
+	rp, err := find.ProcessByPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	runtimeProcess := rp.Details()
+
+	if runtimeProcess.CPU.TicksPerSecond == 0 || runtimeProcess.CPU.NumThreads <= 0 {
+		t.Fatalf("bad: %#v", runtimeProcess.CPU)
+	}
+
+	if runtimeProcess.Memory.ResidentSize == 0 || runtimeProcess.Memory.VirtualSize < runtimeProcess.Memory.ResidentSize {
+		t.Fatalf("bad: %#v", runtimeProcess.Memory)
+	}
+}
+
+func TestProcessIO(t *testing.T) {
+	rp, err := find.ProcessByPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	runtimeProcess := rp.Details()
+
+	// always readable for our own process
+	if !runtimeProcess.IO.Available || runtimeProcess.IO.ReadSyscalls == 0 {
+		t.Fatalf("bad: %#v", runtimeProcess.IO)
+	}
+}
@@ -0,0 +1,80 @@
+// +build !windows
+
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestQuery(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	cmd.Env = []string{"SYSTEMKIT_QUERY_TEST=yes"}
+	cmd.Dir = os.TempDir()
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	rps, err := find.NewQuery().
+		ExecutableName("sleep").
+		ParentProcessID(os.Getpid()).
+		WorkingDirectory(os.TempDir()).
+		CommandLineContains("sleep 30").
+		Environment("SYSTEMKIT_QUERY_TEST", "yes").
+		NewerThan(time.Hour).
+		RunDetails()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(rps) != 1 || rps[0].ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", rps)
+	}
+
+	rps, err = find.NewQuery().
+		ExecutableNameGlob("slee?").
+		ParentProcessID(os.Getpid()).
+		EnvironmentKey("SYSTEMKIT_QUERY_TEST_MISSING").
+		RunDetails()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(rps) != 0 {
+		t.Fatalf("bad: %#v", rps)
+	}
+}
+
+func TestQueryLimitAndSort(t *testing.T) {
+	rps, err := find.NewQuery().
+		State(contracts.ProcessStateRunning, contracts.ProcessStateWaitingEvent, contracts.ProcessStateWaitingIO).
+		Fields(contracts.ProcessFieldStatus).
+		SortBy(find.SortByOldest).
+		Limit(2).
+		RunDetails()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(rps) != 2 || rps[0].StartTime.After(rps[1].StartTime) {
+		t.Fatalf("bad: %#v", rps)
+	}
+}
+
+func TestQueryBadRegex(t *testing.T) {
+	_, err := find.NewQuery().ExecutableNameRegex("(").Run()
+	if err == nil {
+		t.Fatal("should fail")
+	}
+}
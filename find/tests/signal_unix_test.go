@@ -0,0 +1,127 @@
+// +build !windows
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+func TestSignal(t *testing.T) {
+	cmd := exec.Command("sleep", "31")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	exited := make(chan bool)
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	time.Sleep(100 * time.Millisecond)
+
+	query := find.NewQuery().ParentProcessID(os.Getpid()).CommandLineContains("sleep 31")
+
+	// DRY RUN
+	results, err := find.Signal(query, syscall.SIGTERM, find.SignalOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(results) != 1 || results[0].ProcessID != cmd.Process.Pid || results[0].Signalled {
+		t.Fatalf("bad: %#v", results)
+	}
+
+	select {
+	case <-exited:
+		t.Fatal("should not have been signalled")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// FOR REAL
+	results, err = find.Signal(query, syscall.SIGTERM, find.SignalOptions{Newest: true})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(results) != 1 || !results[0].Signalled {
+		t.Fatalf("bad: %#v", results)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("should have exited")
+	}
+}
+
+func TestSignalExcludesSelf(t *testing.T) {
+	results, err := find.Signal(find.NewQuery(), syscall.Signal(0), find.SignalOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, result := range results {
+		if result.ProcessID == os.Getpid() || result.ProcessID == os.Getppid() {
+			t.Fatalf("should not match self or ancestors: %#v", result)
+		}
+	}
+}
+
+func TestSignalReusedPID(t *testing.T) {
+	cmd := exec.Command("sleep", "32")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	exited := make(chan bool)
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	time.Sleep(100 * time.Millisecond)
+
+	matched, err := internal.GetRuntimeProcessByPID(cmd.Process.Pid)
+	if err != nil || len(matched.Identity) == 0 {
+		t.Fatalf("bad: %#v, %v", matched, err)
+	}
+
+	// what a match looks like when the PID got a new owner after it was made
+	stale := matched
+	stale.Identity = fmt.Sprintf("%s:1", stale.Identity[:strings.LastIndex(stale.Identity, ":")])
+
+	if _, err := internal.NewRuningProcessFromRuntimeProcess(stale); err != contracts.ErrProcessDoesNotExist {
+		t.Fatalf("bad: %v", err)
+	}
+
+	select {
+	case <-exited:
+		t.Fatal("should not have been signalled")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	rp, err := internal.NewRuningProcessFromRuntimeProcess(matched)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("should have exited")
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func BenchmarkAllProcesses(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := find.AllProcesses(); err != nil {
+			b.Fatalf("err: %s", err)
+		}
+	}
+}
+
+func BenchmarkSnapshotStatus(b *testing.B) {
+	benchmarkSnapshot(b, contracts.ProcessFieldStatus, 0)
+}
+
+func BenchmarkSnapshotStatusSerial(b *testing.B) {
+	benchmarkSnapshot(b, contracts.ProcessFieldStatus, 1)
+}
+
+func BenchmarkSnapshotStatusStat(b *testing.B) {
+	benchmarkSnapshot(b, contracts.ProcessFieldStatus|contracts.ProcessFieldStat, 0)
+}
+
+func BenchmarkSnapshotAll(b *testing.B) {
+	benchmarkSnapshot(b, contracts.ProcessFieldsAll, 0)
+}
+
+func BenchmarkSnapshotAllSerial(b *testing.B) {
+	benchmarkSnapshot(b, contracts.ProcessFieldsAll, 1)
+}
+
+func BenchmarkQueryByName(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := find.NewQuery().ExecutableName("init").RunDetails(); err != nil {
+			b.Fatalf("err: %s", err)
+		}
+	}
+}
+
+func BenchmarkTree(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := find.Tree(); err != nil {
+			b.Fatalf("err: %s", err)
+		}
+	}
+}
+
+func benchmarkSnapshot(b *testing.B, fields contracts.ProcessFields, workers int) {
+	for i := 0; i < b.N; i++ {
+		var err error
+		if workers > 0 {
+			_, err = find.NewSnapshotWithWorkers(fields, workers)
+		} else {
+			_, err = find.NewSnapshot(fields)
+		}
+		if err != nil {
+			b.Fatalf("err: %s", err)
+		}
+	}
+}
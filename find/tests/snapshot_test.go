@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestSnapshot(t *testing.T) {
+	snapshot, err := find.NewSnapshot(contracts.ProcessFieldStatus)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	found := false
+	for i, rp := range snapshot.Processes() {
+		if i > 0 && snapshot.Processes()[i-1].ProcessID >= rp.ProcessID {
+			t.Fatalf("bad order: %d after %d", rp.ProcessID, snapshot.Processes()[i-1].ProcessID)
+		}
+		if rp.ProcessID == os.Getpid() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("own PID %d is missing", os.Getpid())
+	}
+
+	snapshot.Load(contracts.ProcessFieldCommandLine)
+	if snapshot.Fields()&contracts.ProcessFieldCommandLine == 0 {
+		t.Fatalf("bad fields: %d", snapshot.Fields())
+	}
+
+	for _, rp := range snapshot.Processes() {
+		if rp.ProcessID == os.Getpid() && len(rp.Executable) == 0 {
+			t.Fatalf("bad: %#v", rp)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// +build !windows
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestTree(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+
+	tree, err := find.Tree()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	children := tree.Children(cmd.Process.Pid)
+	if len(children) != 1 || children[0].ExecutableName != "sleep" {
+		t.Fatalf("bad: %#v", children)
+	}
+
+	descendants := tree.Descendants(os.Getpid())
+	if len(descendants) < 2 {
+		t.Fatalf("bad: %#v", descendants)
+	}
+
+	ancestors := tree.Ancestors(children[0].ProcessID)
+	if len(ancestors) < 2 || ancestors[0].ProcessID != cmd.Process.Pid || ancestors[1].ProcessID != os.Getpid() {
+		t.Fatalf("bad: %#v", ancestors)
+	}
+
+	usage := tree.SubtreeUsage(cmd.Process.Pid)
+	if usage.Processes != 2 || usage.ResidentSize == 0 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	rendered := tree.Render(cmd.Process.Pid, find.TreeRenderOptions{ShowPIDs: true, ShowArgs: true})
+	if !strings.Contains(rendered, fmt.Sprintf("sleep(%d) 30", children[0].ProcessID)) || !strings.Contains(rendered, "└─ ") {
+		t.Fatalf("bad: %s", rendered)
+	}
+}
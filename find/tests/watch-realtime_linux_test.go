@@ -0,0 +1,107 @@
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+func TestWatchRealtime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := find.WatchRealtime(ctx, 100*time.Millisecond, find.NewQuery().ExecutableName("sleep").ParentProcessID(os.Getpid()))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	event := nextProcessEvent(t, events)
+	if event.Type != contracts.ProcessEventStarted || event.Process.ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	// the exec can be reported as a change when the fork was seen first
+	event = nextProcessEvent(t, events)
+	for event.Type == contracts.ProcessEventChanged {
+		event = nextProcessEvent(t, events)
+	}
+	if event.Type != contracts.ProcessEventExited || event.Process.ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+// TestWatchRealtimeUserNamespace - the kernel silently ignores proc connector subscriptions from a
+// non-initial user namespace, the watcher has to notice and poll instead of reporting nothing
+func TestWatchRealtimeUserNamespace(t *testing.T) {
+	if os.Getenv("WATCH_REALTIME_IN_USERNS") == "1" {
+		watchRealtimeInUserNamespace(t)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWatchRealtimeUserNamespace$", "-test.v")
+	cmd.Env = append(os.Environ(), "WATCH_REALTIME_IN_USERNS=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil && strings.Contains(err.Error(), "operation not permitted") {
+		t.Skip("user namespaces are not available")
+	}
+	if err != nil || !strings.Contains(string(output), "--- PASS") {
+		t.Fatalf("err: %v, %s", err, output)
+	}
+}
+
+func watchRealtimeInUserNamespace(t *testing.T) {
+	if connector, err := internal.OpenProcConnector(); err == nil {
+		connector.Close()
+		t.Fatal("the proc connector should not open in a user namespace")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := find.WatchRealtime(ctx, 100*time.Millisecond, find.NewQuery().ExecutableName("sleep").ParentProcessID(os.Getpid()))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	event := nextProcessEvent(t, events)
+	if event.Type != contracts.ProcessEventStarted || event.Process.ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", event)
+	}
+}
@@ -0,0 +1,76 @@
+// +build !windows
+
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/find"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := find.Watch(ctx, 100*time.Millisecond, find.NewQuery().ExecutableName("sleep").ParentProcessID(os.Getpid()))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	event := nextProcessEvent(t, events)
+	if event.Type != contracts.ProcessEventStarted || event.Process.ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	cmd.Process.Signal(os.Interrupt)
+	cmd.Wait()
+
+	event = nextProcessEvent(t, events)
+	if event.Type != contracts.ProcessEventExited || event.Process.ProcessID != cmd.Process.Pid {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestDiffProcesses(t *testing.T) {
+	previous := map[string]contracts.RuntimeProcess{
+		"b:10:1": {ProcessID: 10, Identity: "b:10:1", Executable: "a", State: contracts.ProcessStateRunning},
+		"b:11:1": {ProcessID: 11, Identity: "b:11:1", Executable: "b", State: contracts.ProcessStateRunning},
+	}
+	current := map[string]contracts.RuntimeProcess{
+		"b:10:5": {ProcessID: 10, Identity: "b:10:5", Executable: "c", State: contracts.ProcessStateRunning},
+		"b:11:1": {ProcessID: 11, Identity: "b:11:1", Executable: "b", Args: []string{"x"}, State: contracts.ProcessStateWaitingEvent},
+	}
+
+	events := find.DiffProcesses(previous, current, time.Now())
+	if len(events) != 3 ||
+		events[0].Type != contracts.ProcessEventExited || events[0].Process.Identity != "b:10:1" ||
+		events[1].Type != contracts.ProcessEventStarted || events[1].Process.Identity != "b:10:5" ||
+		events[2].Type != contracts.ProcessEventChanged || len(events[2].Changes) != 1 || events[2].Changes[0] != contracts.ProcessChangeCommandLine {
+		t.Fatalf("bad: %#v", events)
+	}
+}
+
+func nextProcessEvent(t *testing.T, events <-chan contracts.ProcessEvent) contracts.ProcessEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+
+	return contracts.ProcessEvent{}
+}
@@ -0,0 +1,213 @@
+package find
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// ProcessTree - parent/child relationships from a single snapshot
+type ProcessTree struct {
+	processes map[int]contracts.RuntimeProcess
+	children  map[int][]int
+}
+
+// SubtreeUsage - resources used by a process and all its descendants
+type SubtreeUsage struct {
+	Processes    int    `json:"processes"`
+	CPUTicks     uint64 `json:"cpuTicks"` // user + system
+	ResidentSize uint64 `json:"residentSize"`
+	VirtualSize  uint64 `json:"virtualSize"`
+}
+
+// TreeRenderOptions - what `Render()` shows for each process
+type TreeRenderOptions struct {
+	ShowPIDs bool
+	ShowArgs bool
+}
+
+// Tree - snapshots all processes once and links them by parent PID
+func Tree() (*ProcessTree, error) {
+	snapshot, err := NewSnapshot(contracts.ProcessFieldStatus | contracts.ProcessFieldStat | contracts.ProcessFieldCommandLine)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProcessTree(snapshot.Processes()), nil
+}
+
+// NewProcessTree - links `rps` by parent PID
+func NewProcessTree(rps []contracts.RuntimeProcess) *ProcessTree {
+	thisRef := &ProcessTree{
+		processes: map[int]contracts.RuntimeProcess{},
+		children:  map[int][]int{},
+	}
+
+	for _, rp := range rps {
+		thisRef.processes[rp.ProcessID] = rp
+	}
+
+	for _, rp := range rps {
+		if rp.ParentProcessID == rp.ProcessID {
+			continue
+		}
+
+		thisRef.children[rp.ParentProcessID] = append(thisRef.children[rp.ParentProcessID], rp.ProcessID)
+	}
+
+	for ppid := range thisRef.children {
+		sort.Ints(thisRef.children[ppid])
+	}
+
+	return thisRef
+}
+
+// Process - the details of `pid` as of the snapshot
+func (thisRef *ProcessTree) Process(pid int) (contracts.RuntimeProcess, bool) {
+	rp, ok := thisRef.processes[pid]
+	return rp, ok
+}
+
+// Roots - processes whose parent is not part of the snapshot, like PID 1 and kernel threads' parent
+func (thisRef *ProcessTree) Roots() []int {
+	roots := []int{}
+	for pid, rp := range thisRef.processes {
+		if _, ok := thisRef.processes[rp.ParentProcessID]; !ok || rp.ParentProcessID == pid {
+			roots = append(roots, pid)
+		}
+	}
+	sort.Ints(roots)
+
+	return roots
+}
+
+// Children - direct children of `pid`
+func (thisRef *ProcessTree) Children(pid int) []contracts.RuntimeProcess {
+	results := []contracts.RuntimeProcess{}
+	for _, childPID := range thisRef.children[pid] {
+		results = append(results, thisRef.processes[childPID])
+	}
+
+	return results
+}
+
+// Descendants - children of `pid`, their children and so on, depth first
+func (thisRef *ProcessTree) Descendants(pid int) []contracts.RuntimeProcess {
+	results := []contracts.RuntimeProcess{}
+	thisRef.walk(pid, func(rp contracts.RuntimeProcess, depth int) {
+		if depth > 0 {
+			results = append(results, rp)
+		}
+	})
+
+	return results
+}
+
+// Ancestors - parent of `pid`, its parent and so on up to the root
+func (thisRef *ProcessTree) Ancestors(pid int) []contracts.RuntimeProcess {
+	results := []contracts.RuntimeProcess{}
+	visited := map[int]bool{pid: true}
+
+	rp, ok := thisRef.processes[pid]
+	for ok {
+		parent, found := thisRef.processes[rp.ParentProcessID]
+		if !found || visited[parent.ProcessID] {
+			break
+		}
+
+		visited[parent.ProcessID] = true
+		results = append(results, parent)
+		rp = parent
+	}
+
+	return results
+}
+
+// SubtreeUsage - CPU and memory of `pid` and all its descendants added up
+func (thisRef *ProcessTree) SubtreeUsage(pid int) SubtreeUsage {
+	usage := SubtreeUsage{}
+	thisRef.walk(pid, func(rp contracts.RuntimeProcess, depth int) {
+		usage.Processes++
+		usage.CPUTicks += rp.CPU.UserTicks + rp.CPU.SystemTicks
+		usage.ResidentSize += rp.Memory.ResidentSize
+		usage.VirtualSize += rp.Memory.VirtualSize
+	})
+
+	return usage
+}
+
+// Render - a `pstree` style text rendering of `pid` and its descendants, or the whole tree if `pid` is 0
+func (thisRef *ProcessTree) Render(pid int, opts TreeRenderOptions) string {
+	roots := []int{pid}
+	if pid == 0 {
+		roots = thisRef.Roots()
+	}
+
+	var sb strings.Builder
+	for _, root := range roots {
+		thisRef.render(&sb, root, "", "", opts)
+	}
+
+	return sb.String()
+}
+
+func (thisRef *ProcessTree) render(sb *strings.Builder, pid int, prefix string, childPrefix string, opts TreeRenderOptions) {
+	rp, ok := thisRef.processes[pid]
+	if !ok {
+		return
+	}
+
+	sb.WriteString(prefix)
+	sb.WriteString(renderTreeLabel(rp, opts))
+	sb.WriteString("\n")
+
+	children := thisRef.children[pid]
+	for i, childPID := range children {
+		if i == len(children)-1 {
+			thisRef.render(sb, childPID, childPrefix+"└─ ", childPrefix+"   ", opts)
+		} else {
+			thisRef.render(sb, childPID, childPrefix+"├─ ", childPrefix+"│  ", opts)
+		}
+	}
+}
+
+func renderTreeLabel(rp contracts.RuntimeProcess, opts TreeRenderOptions) string {
+	label := rp.ExecutableName
+	if opts.ShowPIDs {
+		label = fmt.Sprintf("%s(%d)", label, rp.ProcessID)
+	}
+	if opts.ShowArgs && len(rp.Args) > 0 {
+		label = label + " " + strings.Join(rp.Args, " ")
+	}
+
+	return label
+}
+
+// walk - visits `pid` and its descendants depth first, a cycle can't loop forever
+func (thisRef *ProcessTree) walk(pid int, visit func(rp contracts.RuntimeProcess, depth int)) {
+	visited := map[int]bool{}
+
+	var walk func(pid int, depth int)
+	walk = func(pid int, depth int) {
+		if visited[pid] {
+			return
+		}
+		visited[pid] = true
+
+		rp, ok := thisRef.processes[pid]
+		if !ok {
+			return
+		}
+
+		visit(rp, depth)
+
+		for _, childPID := range thisRef.children[pid] {
+			walk(childPID, depth+1)
+		}
+	}
+
+	walk(pid, 0)
+}
+
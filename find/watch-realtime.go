@@ -0,0 +1,203 @@
+package find
+
+import (
+	"context"
+	"time"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+const logID = "PROCESS-FIND"
+
+// WatchRealtime - same events as `Watch()`, but on Linux they come from the kernel proc connector as they
+// happen, so short lived processes are seen too. Falls back to `Watch()` with `interval` when the proc connector
+// is not available, on other platforms or without CAP_NET_ADMIN.
+// A process that exits before its details can be read is still reported when `query` has no predicates,
+// with only `ProcessID` set.
+func WatchRealtime(ctx context.Context, interval time.Duration, query *Query) (<-chan contracts.ProcessEvent, error) {
+	connector, err := internal.OpenProcConnector()
+	if err != nil {
+		logging.Infof("%s: proc connector not available [%s], polling every %s", logID, err.Error(), interval)
+		return Watch(ctx, interval, query)
+	}
+
+	if query == nil {
+		query = NewQuery()
+	}
+
+	query = query.clone().Fields(query.fields | watchFields)
+
+	// open the connector first, whatever starts from now on is seen
+	initial, err := watchSnapshot(query)
+	if err != nil {
+		connector.Close()
+		return nil, err
+	}
+
+	tracked := map[int]contracts.RuntimeProcess{}
+	for _, rp := range initial {
+		tracked[rp.ProcessID] = rp
+	}
+
+	events := make(chan contracts.ProcessEvent, 100)
+	go func() {
+		defer close(events)
+
+		watcher := &realtimeWatcher{
+			query:   query,
+			tracked: tracked,
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				connector.Close()
+				return
+			default:
+			}
+
+			connectorEvents, err := connector.Read()
+			if err == internal.ErrProcConnectorOverrun {
+				// the kernel dropped events, catch up with a snapshot
+				logging.Warningf("%s: proc connector overrun, resyncing", logID)
+				if !sendProcessEvents(ctx, events, watcher.resync()) {
+					connector.Close()
+					return
+				}
+
+				continue
+			}
+			if err != nil {
+				logging.Errorf("%s: proc connector read-FAIL [%s], polling every %s", logID, err.Error(), interval)
+				connector.Close()
+
+				pollProcessEvents(ctx, interval, query, watcher.identities(), events)
+				return
+			}
+
+			for _, connectorEvent := range connectorEvents {
+				if !sendProcessEvents(ctx, events, watcher.handle(connectorEvent)) {
+					connector.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// realtimeWatcher - turns proc connector events into `contracts.ProcessEvent`s for the processes matching `query`
+type realtimeWatcher struct {
+	query   *Query
+	tracked map[int]contracts.RuntimeProcess // the processes matching `query`, by PID
+}
+
+func (thisRef *realtimeWatcher) handle(connectorEvent internal.ProcConnectorEvent) []contracts.ProcessEvent {
+	now := time.Now()
+	pid := connectorEvent.ProcessID
+	before, isTracked := thisRef.tracked[pid]
+
+	if connectorEvent.Type == internal.ProcConnectorEventExit {
+		if !isTracked {
+			return nil
+		}
+
+		delete(thisRef.tracked, pid)
+		return []contracts.ProcessEvent{{Type: contracts.ProcessEventExited, Time: now, Process: before}}
+	}
+
+	events := []contracts.ProcessEvent{}
+
+	rp, matches := thisRef.query.Match(pid)
+	if !matches {
+		if isTracked {
+			// stopped matching, same as the polling watcher
+			delete(thisRef.tracked, pid)
+			return append(events, contracts.ProcessEvent{Type: contracts.ProcessEventExited, Time: now, Process: before})
+		}
+
+		// already gone, at least report that it existed
+		if len(thisRef.query.predicates) == 0 && rp.State == contracts.ProcessStateNonExistent &&
+			(connectorEvent.Type == internal.ProcConnectorEventFork || connectorEvent.Type == internal.ProcConnectorEventExec) {
+			rp = contracts.RuntimeProcess{
+				ProcessID:       pid,
+				ParentProcessID: connectorEvent.ParentProcessID,
+			}
+			thisRef.tracked[pid] = rp
+			return append(events, contracts.ProcessEvent{Type: contracts.ProcessEventStarted, Time: now, Process: rp})
+		}
+
+		return nil
+	}
+
+	thisRef.tracked[pid] = rp
+
+	// an exit went unnoticed and the PID was reused
+	if isTracked && len(before.Identity) > 0 && before.Identity != rp.Identity {
+		events = append(events, contracts.ProcessEvent{Type: contracts.ProcessEventExited, Time: now, Process: before})
+		isTracked = false
+	}
+
+	if !isTracked {
+		return append(events, contracts.ProcessEvent{Type: contracts.ProcessEventStarted, Time: now, Process: rp})
+	}
+
+	changes := processChanges(before, rp)
+	switch connectorEvent.Type {
+	case internal.ProcConnectorEventExec:
+		changes = append(changes, contracts.ProcessChangeExec)
+	case internal.ProcConnectorEventUID:
+		if before.UserID != rp.UserID {
+			changes = append(changes, contracts.ProcessChangeUserID)
+		}
+	case internal.ProcConnectorEventComm:
+		if before.ExecutableName != rp.ExecutableName {
+			changes = append(changes, contracts.ProcessChangeExecutableName)
+		}
+	}
+
+	if len(changes) > 0 {
+		events = append(events, contracts.ProcessEvent{
+			Type:     contracts.ProcessEventChanged,
+			Time:     now,
+			Process:  rp,
+			Previous: &before,
+			Changes:  changes,
+		})
+	}
+
+	return events
+}
+
+// resync - what changed between the tracked processes and a fresh snapshot
+func (thisRef *realtimeWatcher) resync() []contracts.ProcessEvent {
+	current, err := watchSnapshot(thisRef.query)
+	if err != nil {
+		return nil
+	}
+
+	events := DiffProcesses(thisRef.identities(), current, time.Now())
+
+	thisRef.tracked = map[int]contracts.RuntimeProcess{}
+	for _, rp := range current {
+		thisRef.tracked[rp.ProcessID] = rp
+	}
+
+	return events
+}
+
+// identities - the tracked processes keyed the way `DiffProcesses()` wants them
+func (thisRef *realtimeWatcher) identities() map[string]contracts.RuntimeProcess {
+	results := make(map[string]contracts.RuntimeProcess, len(thisRef.tracked))
+	for _, rp := range thisRef.tracked {
+		if len(rp.Identity) == 0 {
+			continue // seen only by PID, the exit will come from the connector
+		}
+		results[rp.Identity] = rp
+	}
+
+	return results
+}
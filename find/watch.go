@@ -0,0 +1,170 @@
+package find
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// watchFields - what a watcher needs to tell processes apart and to notice changes
+const watchFields = contracts.ProcessFieldStatus | contracts.ProcessFieldStat | contracts.ProcessFieldCommandLine | contracts.ProcessFieldWorkingDirectory
+
+// Watch - runs `query` every `interval` and emits an event for every matching process that started,
+// exited or changed state (stopped, zombie), command line or working directory since the previous run.
+// A process that stops matching the query is reported as exited. `query` can be `nil` to watch all processes.
+// The first run only records what is already there. The channel is closed once `ctx` is done.
+func Watch(ctx context.Context, interval time.Duration, query *Query) (<-chan contracts.ProcessEvent, error) {
+	if query == nil {
+		query = NewQuery()
+	}
+
+	query = query.clone().Fields(query.fields | watchFields)
+
+	previous, err := watchSnapshot(query)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan contracts.ProcessEvent, 100)
+	go func() {
+		defer close(events)
+		pollProcessEvents(ctx, interval, query, previous, events)
+	}()
+
+	return events, nil
+}
+
+// pollProcessEvents - runs `query` every `interval` until `ctx` is done and sends what changed since `previous`
+func pollProcessEvents(ctx context.Context, interval time.Duration, query *Query, previous map[string]contracts.RuntimeProcess, events chan<- contracts.ProcessEvent) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := watchSnapshot(query)
+		if err != nil {
+			continue
+		}
+
+		if !sendProcessEvents(ctx, events, DiffProcesses(previous, current, time.Now())) {
+			return
+		}
+
+		previous = current
+	}
+}
+
+// sendProcessEvents - `false` if `ctx` got done before all were sent
+func sendProcessEvents(ctx context.Context, events chan<- contracts.ProcessEvent, toSend []contracts.ProcessEvent) bool {
+	for _, event := range toSend {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// DiffProcesses - the events that turn `previous` into `current`, both keyed by `RuntimeProcess.Identity`
+func DiffProcesses(previous map[string]contracts.RuntimeProcess, current map[string]contracts.RuntimeProcess, now time.Time) []contracts.ProcessEvent {
+	events := []contracts.ProcessEvent{}
+
+	for identity, rp := range previous {
+		if _, ok := current[identity]; !ok {
+			events = append(events, contracts.ProcessEvent{
+				Type:    contracts.ProcessEventExited,
+				Time:    now,
+				Process: rp,
+			})
+		}
+	}
+
+	for identity, rp := range current {
+		before, ok := previous[identity]
+		if !ok {
+			events = append(events, contracts.ProcessEvent{
+				Type:    contracts.ProcessEventStarted,
+				Time:    now,
+				Process: rp,
+			})
+
+			continue
+		}
+
+		changes := processChanges(before, rp)
+		if len(changes) > 0 {
+			previousCopy := before
+			events = append(events, contracts.ProcessEvent{
+				Type:     contracts.ProcessEventChanged,
+				Time:     now,
+				Process:  rp,
+				Previous: &previousCopy,
+				Changes:  changes,
+			})
+		}
+	}
+
+	// exits first, then by PID, so a reused PID reads naturally
+	sortProcessEvents(events)
+
+	return events
+}
+
+func watchSnapshot(query *Query) (map[string]contracts.RuntimeProcess, error) {
+	rps, err := query.RunDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]contracts.RuntimeProcess, len(rps))
+	for _, rp := range rps {
+		results[rp.Identity] = rp
+	}
+
+	return results, nil
+}
+
+func processChanges(before contracts.RuntimeProcess, after contracts.RuntimeProcess) []string {
+	changes := []string{}
+
+	if watchedState(before.State) != watchedState(after.State) {
+		changes = append(changes, contracts.ProcessChangeState)
+	}
+	if CommandLine(before) != CommandLine(after) {
+		changes = append(changes, contracts.ProcessChangeCommandLine)
+	}
+	if before.WorkingDirectory != after.WorkingDirectory {
+		changes = append(changes, contracts.ProcessChangeWorkingDirectory)
+	}
+
+	return changes
+}
+
+func sortProcessEvents(events []contracts.ProcessEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if (events[i].Type == contracts.ProcessEventExited) != (events[j].Type == contracts.ProcessEventExited) {
+			return events[i].Type == contracts.ProcessEventExited
+		}
+
+		return events[i].Process.ProcessID < events[j].Process.ProcessID
+	})
+}
+
+// watchedState - running, sleeping and waiting on IO flip all the time, only report moving out of them
+func watchedState(state contracts.ProcessState) contracts.ProcessState {
+	switch state {
+	case contracts.ProcessStateWaitingIO, contracts.ProcessStateWaitingEvent, contracts.ProcessStatePaging:
+		return contracts.ProcessStateRunning
+	}
+
+	return state
+}
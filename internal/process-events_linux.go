@@ -0,0 +1,295 @@
+// +build linux
+
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// from linux/connector.h and linux/cn_proc.h
+const (
+	_CN_IDX_PROC          = 1
+	_CN_VAL_PROC          = 1
+	_PROC_CN_MCAST_LISTEN = 1
+
+	_PROC_EVENT_NONE = 0x00000000 // the ack to `PROC_CN_MCAST_LISTEN`
+	_PROC_EVENT_FORK = 0x00000001
+	_PROC_EVENT_EXEC = 0x00000002
+	_PROC_EVENT_UID  = 0x00000004
+	_PROC_EVENT_COMM = 0x00000200
+	_PROC_EVENT_EXIT = 0x80000000
+
+	_CN_MSG_SIZE        = 20 // struct cn_msg without data
+	_PROC_EVENT_HDR_LEN = 16 // what, cpu, timestamp_ns
+)
+
+// ErrProcConnectorOverrun - events were lost
+var ErrProcConnectorOverrun = errors.New("ErrProcConnectorOverrun")
+
+// ErrProcConnectorNoAck - the kernel never confirmed the subscription, it ignores subscriptions from
+// outside the initial user and PID namespaces without a word
+var ErrProcConnectorNoAck = errors.New("ErrProcConnectorNoAck")
+
+// procConnectorAckTimeout - how long `OpenProcConnector()` waits for the kernel to confirm the subscription
+const procConnectorAckTimeout = 2 * time.Second
+
+// ProcConnectorEventType -
+type ProcConnectorEventType int
+
+// ProcConnectorEventFork -
+const (
+	ProcConnectorEventFork ProcConnectorEventType = iota
+	ProcConnectorEventExec
+	ProcConnectorEventUID
+	ProcConnectorEventComm
+	ProcConnectorEventExit
+)
+
+// ProcConnectorEvent - one process (not thread) event from the kernel
+type ProcConnectorEvent struct {
+	Type            ProcConnectorEventType
+	ProcessID       int
+	ParentProcessID int // only for `ProcConnectorEventFork`
+	ExitCode        int // only for `ProcConnectorEventExit`, the raw wait status
+}
+
+// ProcConnector - the Linux proc connector, a netlink socket the kernel reports every fork, exec and exit on
+type ProcConnector struct {
+	fd  int
+	buf []byte
+}
+
+// OpenProcConnector - subscribes to proc events, needs CAP_NET_ADMIN in the initial user and PID namespaces,
+// fails with `EPERM` without them, or with `ErrProcConnectorNoAck` if the kernel doesn't confirm the subscription
+func OpenProcConnector() (*ProcConnector, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	thisRef := &ProcConnector{
+		fd:  fd,
+		buf: make([]byte, os.Getpagesize()),
+	}
+
+	if err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: _CN_IDX_PROC}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	// so `Read()` returns now and then and the caller can check if it should stop
+	if err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Sec: 0, Usec: 500000}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	// binding works anywhere, whether the kernel reports anything only shows in its ack
+	ack := uint32(time.Now().UnixNano())
+	if err = thisRef.setListening(true, ack); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	if err = thisRef.waitForAck(ack); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return thisRef, nil
+}
+
+// waitForAck - reads until the kernel answers the message sent with `ack`, events that come first are dropped
+func (thisRef *ProcConnector) waitForAck(ack uint32) error {
+	deadline := time.Now().Add(procConnectorAckTimeout)
+	for time.Now().Before(deadline) {
+		n, _, err := unix.Recvfrom(thisRef.fd, thisRef.buf, 0)
+		if err == unix.EAGAIN || err == unix.EINTR || err == unix.ENOBUFS {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(thisRef.buf[:n])
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if msg.Header.Type != unix.NLMSG_DONE {
+				continue
+			}
+
+			if isAck, errno := parseProcConnectorAck(msg.Data, ack); isAck {
+				if errno != 0 {
+					return errno
+				}
+				return nil
+			}
+		}
+	}
+
+	return ErrProcConnectorNoAck
+}
+
+// parseProcConnectorAck - if `data` is the ack to the message sent with `ack` and the error it carries,
+// the kernel answers with `ack` + 1, the sequence is its own
+func parseProcConnectorAck(data []byte, ack uint32) (bool, syscall.Errno) {
+	if len(data) < _CN_MSG_SIZE+_PROC_EVENT_HDR_LEN+4 {
+		return false, 0
+	}
+
+	if nativeEndian.Uint32(data[0:]) != _CN_IDX_PROC || nativeEndian.Uint32(data[4:]) != _CN_VAL_PROC {
+		return false, 0
+	}
+
+	if nativeEndian.Uint32(data[12:]) != ack+1 {
+		return false, 0
+	}
+
+	if nativeEndian.Uint32(data[_CN_MSG_SIZE:]) != _PROC_EVENT_NONE {
+		return false, 0
+	}
+
+	return true, syscall.Errno(nativeEndian.Uint32(data[_CN_MSG_SIZE+_PROC_EVENT_HDR_LEN:]))
+}
+
+// Read - waits up to half a second for events, returns `ErrProcConnectorOverrun` if the kernel dropped events
+// because they were not read fast enough
+func (thisRef *ProcConnector) Read() ([]ProcConnectorEvent, error) {
+	n, _, err := unix.Recvfrom(thisRef.fd, thisRef.buf, 0)
+	if err == unix.EAGAIN || err == unix.EINTR {
+		return nil, nil
+	}
+	if err == unix.ENOBUFS {
+		return nil, ErrProcConnectorOverrun
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := syscall.ParseNetlinkMessage(thisRef.buf[:n])
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ProcConnectorEvent{}
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.NLMSG_DONE {
+			continue
+		}
+
+		event, ok := parseProcConnectorEvent(msg.Data)
+		if ok {
+			results = append(results, event)
+		}
+	}
+
+	return results, nil
+}
+
+// Close - unsubscribes and closes the socket
+func (thisRef *ProcConnector) Close() error {
+	thisRef.setListening(false, 0)
+	return unix.Close(thisRef.fd)
+}
+
+func (thisRef *ProcConnector) setListening(listen bool, ack uint32) error {
+	op := uint32(_PROC_CN_MCAST_LISTEN)
+	if !listen {
+		op = _PROC_CN_MCAST_LISTEN + 1 // PROC_CN_MCAST_IGNORE
+	}
+
+	msg := make([]byte, unix.NLMSG_HDRLEN+_CN_MSG_SIZE+4)
+
+	// struct nlmsghdr
+	nativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:], unix.NLMSG_DONE)
+	nativeEndian.PutUint32(msg[12:], uint32(os.Getpid()))
+
+	// struct cn_msg
+	cn := msg[unix.NLMSG_HDRLEN:]
+	nativeEndian.PutUint32(cn[0:], _CN_IDX_PROC)
+	nativeEndian.PutUint32(cn[4:], _CN_VAL_PROC)
+	nativeEndian.PutUint32(cn[12:], ack)
+	nativeEndian.PutUint16(cn[16:], 4)
+
+	// enum proc_cn_mcast_op
+	nativeEndian.PutUint32(cn[_CN_MSG_SIZE:], op)
+
+	return unix.Sendto(thisRef.fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+// parseProcConnectorEvent - parses a `struct cn_msg` carrying a `struct proc_event`, thread events are skipped
+func parseProcConnectorEvent(data []byte) (ProcConnectorEvent, bool) {
+	if len(data) < _CN_MSG_SIZE+_PROC_EVENT_HDR_LEN {
+		return ProcConnectorEvent{}, false
+	}
+
+	if nativeEndian.Uint32(data[0:]) != _CN_IDX_PROC || nativeEndian.Uint32(data[4:]) != _CN_VAL_PROC {
+		return ProcConnectorEvent{}, false
+	}
+
+	what := nativeEndian.Uint32(data[_CN_MSG_SIZE:])
+	body := data[_CN_MSG_SIZE+_PROC_EVENT_HDR_LEN:]
+
+	field := func(index int) int {
+		if len(body) < (index+1)*4 {
+			return 0
+		}
+		return int(int32(nativeEndian.Uint32(body[index*4:])))
+	}
+
+	switch what {
+	case _PROC_EVENT_FORK:
+		// parent_pid, parent_tgid, child_pid, child_tgid
+		if field(2) != field(3) {
+			return ProcConnectorEvent{}, false
+		}
+		return ProcConnectorEvent{Type: ProcConnectorEventFork, ProcessID: field(3), ParentProcessID: field(1)}, true
+
+	case _PROC_EVENT_EXEC:
+		// process_pid, process_tgid
+		return ProcConnectorEvent{Type: ProcConnectorEventExec, ProcessID: field(1)}, true
+
+	case _PROC_EVENT_UID:
+		// process_pid, process_tgid, ruid, euid
+		if field(0) != field(1) {
+			return ProcConnectorEvent{}, false
+		}
+		return ProcConnectorEvent{Type: ProcConnectorEventUID, ProcessID: field(1)}, true
+
+	case _PROC_EVENT_COMM:
+		// process_pid, process_tgid, comm[16]
+		if field(0) != field(1) {
+			return ProcConnectorEvent{}, false
+		}
+		return ProcConnectorEvent{Type: ProcConnectorEventComm, ProcessID: field(1)}, true
+
+	case _PROC_EVENT_EXIT:
+		// process_pid, process_tgid, exit_code, exit_signal
+		if field(0) != field(1) {
+			return ProcConnectorEvent{}, false
+		}
+		return ProcConnectorEvent{Type: ProcConnectorEventExit, ProcessID: field(1), ExitCode: field(2)}, true
+	}
+
+	return ProcConnectorEvent{}, false
+}
+
+// nativeEndian - netlink messages use the byte order of the host
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
@@ -0,0 +1,50 @@
+// +build !linux
+
+package internal
+
+import (
+	"errors"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// ErrProcConnectorOverrun - events were lost
+var ErrProcConnectorOverrun = errors.New("ErrProcConnectorOverrun")
+
+// ProcConnectorEventType -
+type ProcConnectorEventType int
+
+// ProcConnectorEventFork -
+const (
+	ProcConnectorEventFork ProcConnectorEventType = iota
+	ProcConnectorEventExec
+	ProcConnectorEventUID
+	ProcConnectorEventComm
+	ProcConnectorEventExit
+)
+
+// ProcConnectorEvent - one process (not thread) event from the kernel
+type ProcConnectorEvent struct {
+	Type            ProcConnectorEventType
+	ProcessID       int
+	ParentProcessID int
+	ExitCode        int
+}
+
+// ProcConnector - only Linux has a proc connector
+type ProcConnector struct{}
+
+// OpenProcConnector - only Linux has a proc connector
+func OpenProcConnector() (*ProcConnector, error) {
+	return nil, contracts.ErrNotAvailable
+}
+
+// Read - only Linux has a proc connector
+func (thisRef *ProcConnector) Read() ([]ProcConnectorEvent, error) {
+	return nil, contracts.ErrNotAvailable
+}
+
+// Close - only Linux has a proc connector
+func (thisRef *ProcConnector) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+// +build linux
+
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// capabilityNames - indexed by capability number, copied from linux/capability.h
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// capabilityNamesFromMask - decodes a hex capability mask from `/proc/<pid>/status`,
+// capabilities newer than the table are named by number like `CAP_41`
+func capabilityNamesFromMask(hexMask string) []string {
+	mask, _ := strconv.ParseUint(strings.TrimSpace(hexMask), 16, 64)
+
+	results := []string{}
+	for capability := 0; capability < 64; capability++ {
+		if mask&(1<<uint(capability)) == 0 {
+			continue
+		}
+
+		if capability < len(capabilityNames) {
+			results = append(results, capabilityNames[capability])
+		} else {
+			results = append(results, fmt.Sprintf("CAP_%d", capability))
+		}
+	}
+
+	return results
+}
+
+// capabilityFromName - accepts `CAP_NET_BIND_SERVICE`, `cap_net_bind_service`, `NET_BIND_SERVICE` or a number
+func capabilityFromName(name string) (uintptr, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "CAP_") {
+		name = "CAP_" + name
+	}
+
+	for capability, capabilityName := range capabilityNames {
+		if capabilityName == name {
+			return uintptr(capability), nil
+		}
+	}
+
+	capability, err := strconv.Atoi(strings.TrimPrefix(name, "CAP_"))
+	if err != nil || capability < 0 || capability > 63 {
+		return 0, fmt.Errorf("unknown capability [%s]", name)
+	}
+
+	return uintptr(capability), nil
+}
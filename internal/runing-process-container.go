@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// containerIDPattern - a 64 hex digits ID, optionally wrapped like `docker-<id>.scope` by systemd
+var containerIDPattern = regexp.MustCompile(`^(?:(docker|cri-containerd|containerd|crio|libpod)-)?([0-9a-f]{64})(?:\.scope)?$`)
+
+// ContainerFromProcCgroup - detects the container from the content of `/proc/<pid>/cgroup`, works with
+// cgroup v1 and v2 and the docker, containerd, podman and kubepods layouts
+func ContainerFromProcCgroup(data string) contracts.ProcessContainer {
+	result := contracts.ProcessContainer{}
+
+	// hierarchy-ID:controllers:path, the v2 path has hierarchy 0 and no controllers
+	for _, line := range strings.Split(data, "\n") {
+		props := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(props) != 3 {
+			continue
+		}
+
+		cgroupPath := props[2]
+		if len(result.CgroupPath) == 0 || (props[0] == "0" && len(props[1]) == 0) {
+			result.CgroupPath = cgroupPath
+		}
+
+		runtime, id := containerFromCgroupPath(cgroupPath)
+		if len(id) > 0 {
+			return contracts.ProcessContainer{
+				CgroupPath: cgroupPath,
+				ID:         id,
+				Runtime:    runtime,
+			}
+		}
+	}
+
+	return result
+}
+
+// containerFromCgroupPath - the innermost path element that looks like a container ID wins
+func containerFromCgroupPath(cgroupPath string) (string, string) {
+	elements := strings.Split(cgroupPath, "/")
+	for i := len(elements) - 1; i >= 0; i-- {
+		match := containerIDPattern.FindStringSubmatch(elements[i])
+		if match == nil {
+			continue
+		}
+
+		if strings.Contains(cgroupPath, "kubepods") {
+			return "kubepods", match[2]
+		}
+
+		prefix := match[1]
+		if len(prefix) == 0 && i > 0 {
+			// `/docker/<id>`, `/containerd/<id>`, `/libpod_parent/<id>`
+			prefix = elements[i-1]
+		}
+
+		switch {
+		case strings.HasPrefix(prefix, "docker"):
+			return "docker", match[2]
+		case strings.HasPrefix(prefix, "libpod"), strings.HasPrefix(prefix, "podman"):
+			return "podman", match[2]
+		case strings.Contains(prefix, "containerd"), strings.HasPrefix(prefix, "crio"):
+			return "containerd", match[2]
+		}
+
+		return "", match[2]
+	}
+
+	return "", ""
+}
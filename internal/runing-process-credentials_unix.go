@@ -0,0 +1,114 @@
+// +build !windows
+
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+var (
+	userNamesSync  = &sync.Mutex{}
+	userNames      = map[int]string{}
+	groupNamesSync = &sync.Mutex{}
+	groupNames     = map[int]string{}
+)
+
+// resolveCredentialNames - fills in the user and group names, lookups are cached since there are far
+// fewer users than processes
+func resolveCredentialNames(credentials *contracts.ProcessCredentials) {
+	credentials.UserName = userName(credentials.EffectiveUserID)
+	credentials.RealUserName = userName(credentials.RealUserID)
+	credentials.GroupName = groupName(credentials.EffectiveGroupID)
+	credentials.RealGroupName = groupName(credentials.RealGroupID)
+}
+
+func userName(uid int) string {
+	userNamesSync.Lock()
+	defer userNamesSync.Unlock()
+
+	name, ok := userNames[uid]
+	if !ok {
+		if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+			name = u.Username
+		}
+		userNames[uid] = name
+	}
+
+	return name
+}
+
+func groupName(gid int) string {
+	groupNamesSync.Lock()
+	defer groupNamesSync.Unlock()
+
+	name, ok := groupNames[gid]
+	if !ok {
+		if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+			name = g.Name
+		}
+		groupNames[gid] = name
+	}
+
+	return name
+}
+
+const ttyRescanInterval = 5 * time.Second
+
+var (
+	ttyNamesSync    = &sync.Mutex{}
+	ttyNames        = map[uint64]string{}
+	ttyNamesScanned = time.Time{}
+)
+
+// ttyNameFromDevice - finds the `/dev` entry of a terminal device number, like `ps` does,
+// terminals come and go so a miss rescans `/dev`, but not more often than every few seconds
+func ttyNameFromDevice(rdev uint64) string {
+	ttyNamesSync.Lock()
+	defer ttyNamesSync.Unlock()
+
+	name, ok := ttyNames[rdev]
+	if !ok && time.Since(ttyNamesScanned) > ttyRescanInterval {
+		ttyNames = scanTTYDevices()
+		ttyNamesScanned = time.Now()
+		name = ttyNames[rdev]
+	}
+
+	return name
+}
+
+func scanTTYDevices() map[uint64]string {
+	results := map[uint64]string{}
+	for _, folder := range []string{"/dev", "/dev/pts"} {
+		fis, err := ioutil.ReadDir(folder)
+		if err != nil {
+			continue
+		}
+
+		for _, fi := range fis {
+			if fi.Mode()&os.ModeCharDevice == 0 {
+				continue
+			}
+
+			stat, ok := fi.Sys().(*syscall.Stat_t)
+			if !ok {
+				continue
+			}
+
+			rdev := uint64(stat.Rdev)
+			if _, exists := results[rdev]; !exists {
+				results[rdev] = path.Join(folder, fi.Name())
+			}
+		}
+	}
+
+	return results
+}
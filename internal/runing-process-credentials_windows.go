@@ -0,0 +1,53 @@
+// +build windows
+
+package internal
+
+import (
+	"golang.org/x/sys/windows"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// readWindowsCredentials - Windows has SIDs instead of numeric IDs, so only the names and the session are set,
+// the token of processes of other users can't be opened without admin rights
+func readWindowsCredentials(pid int, rp *contracts.RuntimeProcess) {
+	var sessionID uint32
+	if windows.ProcessIdToSessionId(uint32(pid), &sessionID) == nil {
+		rp.SessionID = int(sessionID)
+	}
+
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(handle)
+
+	var token windows.Token
+	if err = windows.OpenProcessToken(handle, windows.TOKEN_QUERY, &token); err != nil {
+		return
+	}
+	defer token.Close()
+
+	if tokenUser, err := token.GetTokenUser(); err == nil {
+		rp.Credentials.UserName = windowsAccountName(tokenUser.User.Sid)
+		rp.Credentials.RealUserName = rp.Credentials.UserName
+	}
+
+	if tokenGroup, err := token.GetTokenPrimaryGroup(); err == nil {
+		rp.Credentials.GroupName = windowsAccountName(tokenGroup.PrimaryGroup)
+		rp.Credentials.RealGroupName = rp.Credentials.GroupName
+	}
+}
+
+func windowsAccountName(sid *windows.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+
+	if len(domain) == 0 {
+		return account
+	}
+
+	return domain + "\\" + account
+}
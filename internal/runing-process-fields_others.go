@@ -0,0 +1,54 @@
+// +build !linux
+
+package internal
+
+import (
+	"sort"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// readRuntimeProcessFields - everything comes from one call on this platform, so all fields are read
+func readRuntimeProcessFields(pid int, procMedata *contracts.RuntimeProcess, fields contracts.ProcessFields) (contracts.ProcessFields, error) {
+	rp, err := getRuntimeProcessByPID(pid)
+	if err != nil {
+		return 0, err
+	}
+
+	*procMedata = rp
+
+	return contracts.ProcessFieldsAll, nil
+}
+
+func listProcessIDs() ([]int, error) {
+	rps, err := getAllRuningProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]int, 0, len(rps))
+	for _, rp := range rps {
+		results = append(results, rp.(*runingProcess).processID())
+	}
+
+	return results, nil
+}
+
+// snapshotRuntimeProcesses - the platform lists everything at once, so all fields come for free
+func snapshotRuntimeProcesses(fields contracts.ProcessFields, workers int) ([]contracts.RuntimeProcess, []contracts.ProcessFields, error) {
+	rps, err := GetAllRuntimeProcesses()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(rps, func(i, j int) bool {
+		return rps[i].ProcessID < rps[j].ProcessID
+	})
+
+	loaded := make([]contracts.ProcessFields, len(rps))
+	for i := range loaded {
+		loaded[i] = contracts.ProcessFieldsAll
+	}
+
+	return rps, loaded, nil
+}
@@ -1,7 +1,10 @@
 package internal
 
 import (
-	"os"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/remoteit/systemkit-processes/contracts"
 )
@@ -16,24 +19,95 @@ func GetAllRuningProcesses() ([]contracts.RuningProcess, error) {
 	return getAllRuningProcesses()
 }
 
-func getRuningProcessByPID(pid int) (contracts.RuningProcess, error) {
-	rp, err := getRuntimeProcessByPID(pid)
+// GetRuntimeProcessByPID - returns details for the process with PID
+func GetRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
+	return getRuntimeProcessByPID(pid)
+}
+
+// GetAllRuntimeProcesses - returns details for all processes
+func GetAllRuntimeProcesses() ([]contracts.RuntimeProcess, error) {
+	rps, err := getAllRuningProcesses()
 	if err != nil {
-		return NewEmptyRuningProcess(), contracts.ErrProcessDoesNotExist
+		return nil, err
 	}
 
-	osProcess, err := os.FindProcess(pid)
+	results := make([]contracts.RuntimeProcess, 0, len(rps))
+	for _, rp := range rps {
+		details := rp.Details()
+		if details.State == contracts.ProcessStateNonExistent {
+			continue
+		}
+
+		results = append(results, details)
+	}
+
+	return results, nil
+}
+
+// ListProcessIDs - returns the PIDs of all processes
+func ListProcessIDs() ([]int, error) {
+	return listProcessIDs()
+}
+
+// ReadRuntimeProcessFields - reads `fields` of a process into `rp`, returns the fields that were
+// actually read, which can be more than asked for on platforms that read everything at once
+func ReadRuntimeProcessFields(pid int, rp *contracts.RuntimeProcess, fields contracts.ProcessFields) (contracts.ProcessFields, error) {
+	return readRuntimeProcessFields(pid, rp, fields)
+}
+
+// NewRuningProcessFromRuntimeProcess - wraps details obtained earlier, without reading them again,
+// the process is bound to `rp.Identity`, so if the PID was reused since `ErrProcessDoesNotExist` is returned
+// and a process that owns it later is never signalled
+func NewRuningProcessFromRuntimeProcess(rp contracts.RuntimeProcess) (contracts.RuningProcess, error) {
+	osProcess, err := findProcess(rp.ProcessID)
 	if err != nil {
 		return NewEmptyRuningProcess(), contracts.ErrProcessDoesNotExist
 	}
 
-	return NewRuningProcessWithOSProc(
-		contracts.ProcessTemplate{
-			Executable:       rp.Executable,
-			Args:             rp.Args,
-			WorkingDirectory: rp.WorkingDirectory,
-			Environment:      rp.Environment,
-		},
-		osProcess,
-	), nil
+	processTemplate := contracts.ProcessTemplate{
+		Executable:       rp.Executable,
+		Args:             rp.Args,
+		WorkingDirectory: rp.WorkingDirectory,
+		Environment:      rp.Environment,
+	}
+
+	result := newRuningProcess(processTemplate, false)
+	result.osCmd = exec.Command(processTemplate.Executable, processTemplate.Args...)
+	result.osCmd.Process = osProcess
+	result.handle = newProcessHandleForIdentity(osProcess, rp.Identity)
+
+	if result.handle.isGone() {
+		return NewEmptyRuningProcess(), contracts.ErrProcessDoesNotExist
+	}
+
+	return result, nil
+}
+
+func getRuningProcessByPID(pid int) (contracts.RuningProcess, error) {
+	rp, err := getRuntimeProcessByPID(pid)
+	if err != nil || rp.State == contracts.ProcessStateNonExistent {
+		return NewEmptyRuningProcess(), contracts.ErrProcessDoesNotExist
+	}
+
+	return NewRuningProcessFromRuntimeProcess(rp)
+}
+
+// processIdentity - a key that tells apart two processes that had the same PID at different times
+func processIdentity(bootID string, pid int, startTicks uint64) string {
+	return fmt.Sprintf("%s:%d:%d", bootID, pid, startTicks)
+}
+
+// identityStartTime - the start time part of an identity made by `processIdentity()`, `false` if there is none
+func identityStartTime(identity string) (uint64, bool) {
+	i := strings.LastIndex(identity, ":")
+	if i < 0 {
+		return 0, false
+	}
+
+	startTime, err := strconv.ParseUint(identity[i+1:], 10, 64)
+	if err != nil || startTime == 0 {
+		return 0, false
+	}
+
+	return startTime, true
 }
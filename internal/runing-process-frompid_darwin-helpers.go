@@ -5,42 +5,123 @@ package internal
 import (
 	"bytes"
 	"encoding/binary"
+	"runtime"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
 )
 
+// listAllRuntimeProcesses - what `kern.proc.all` has, the session, scheduling and usage cost a syscall
+// each per process, `getRuntimeProcessByPID()` adds them
 func listAllRuntimeProcesses() ([]contracts.RuntimeProcess, error) {
-	buf, err := darwinSyscall()
+	procs, err := darwinKinfoProcs(_KERN_PROC_ALL, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	procs := make([]*kinfoProc, 0, 50)
-	k := 0
-	for i := _KINFO_STRUCT_SIZE; i < buf.Len(); i += _KINFO_STRUCT_SIZE {
-		proc := &kinfoProc{}
-		err = binary.Read(bytes.NewBuffer(buf.Bytes()[k:i]), binary.LittleEndian, proc)
-		if err != nil {
-			return nil, err
-		}
+	darwinProcs := make([]contracts.RuntimeProcess, len(procs))
+	for i, p := range procs {
+		darwinProcs[i] = runtimeProcessFromKinfoProc(p)
+	}
 
-		k = i
-		procs = append(procs, proc)
+	return darwinProcs, nil
+}
+
+var (
+	darwinBootIDSync = &sync.Once{}
+	darwinBootIDVal  = ""
+)
+
+func darwinBootID() string {
+	darwinBootIDSync.Do(func() {
+		darwinBootIDVal, _ = unix.Sysctl("kern.bootsessionuuid")
+	})
+
+	return darwinBootIDVal
+}
+
+func runtimeProcessFromKinfoProc(p *kinfoProc) contracts.RuntimeProcess {
+	startMicroseconds := uint64(p.StartSec)*1000000 + uint64(p.StartUsec)
+
+	rp := contracts.RuntimeProcess{
+		State:           darwinProcessState(p.Stat),
+		ProcessID:       int(p.Pid),
+		ParentProcessID: int(p.PPid),
+		UserID:          int(p.Ruid),
+		GroupID:         int(p.Rgid),
+		ProcessGroupID:  int(p.Pgid),
+		Executable:      darwinCstring(p.Comm),
+		StartTime:       time.Unix(p.StartSec, int64(p.StartUsec)*1000),
+		Identity:        processIdentity(darwinBootID(), int(p.Pid), startMicroseconds),
+		Credentials:     darwinCredentials(p),
 	}
 
-	darwinProcs := make([]contracts.RuntimeProcess, len(procs))
-	for i, p := range procs {
-		darwinProcs[i] = contracts.RuntimeProcess{
-			State:           contracts.ProcessStateRunning,
-			ProcessID:       int(p.Pid),
-			ParentProcessID: int(p.PPid),
-			Executable:      darwinCstring(p.Comm),
+	if p.Tdev != -1 {
+		rp.TTY = ttyNameFromDevice(uint64(uint32(p.Tdev)))
+	}
+
+	return rp
+}
+
+// readDarwinProcessDetails - the session, scheduling and usage of one process
+func readDarwinProcessDetails(rp *contracts.RuntimeProcess) {
+	if sid, err := unix.Getsid(rp.ProcessID); err == nil {
+		rp.SessionID = sid
+	}
+
+	readProcessScheduling(rp.ProcessID, rp)
+
+	rusage, err := darwinPidRusage(rp.ProcessID)
+	if err == nil {
+		rp.IO = contracts.ProcessIO{
+			Available:  true,
+			ReadBytes:  rusage.DiskioBytesread,
+			WriteBytes: rusage.DiskioByteswritten,
+		}
+		rp.CPU = contracts.ProcessCPU{
+			UserTicks:      darwinMachTimeToNanoseconds(rusage.UserTime),
+			SystemTicks:    darwinMachTimeToNanoseconds(rusage.SystemTime),
+			TicksPerSecond: 1000000000,
 		}
+		rp.Memory.ResidentSize = rusage.ResidentSize
 	}
+}
 
-	return darwinProcs, nil
+// darwinCredentials - macOS has no filesystem IDs, they are the effective ones
+func darwinCredentials(p *kinfoProc) contracts.ProcessCredentials {
+	ngroups := int(p.Ngroups)
+	if ngroups < 0 || ngroups > len(p.Groups) {
+		ngroups = len(p.Groups)
+	}
+
+	groups := make([]int, 0, ngroups)
+	for _, gid := range p.Groups[:ngroups] {
+		groups = append(groups, int(gid))
+	}
+
+	effectiveGroupID := int(p.Rgid)
+	if len(groups) > 0 {
+		effectiveGroupID = groups[0]
+	}
+
+	credentials := contracts.ProcessCredentials{
+		RealUserID:            int(p.Ruid),
+		EffectiveUserID:       int(p.UID),
+		SavedUserID:           int(p.Svuid),
+		FilesystemUserID:      int(p.UID),
+		RealGroupID:           int(p.Rgid),
+		EffectiveGroupID:      effectiveGroupID,
+		SavedGroupID:          int(p.Svgid),
+		FilesystemGroupID:     effectiveGroupID,
+		SupplementaryGroupIDs: groups,
+	}
+	resolveCredentialNames(&credentials)
+
+	return credentials
 }
 
 func darwinCstring(s [16]byte) string {
@@ -56,8 +137,9 @@ func darwinCstring(s [16]byte) string {
 	return string(s[:i])
 }
 
-func darwinSyscall() (*bytes.Buffer, error) {
-	mib := [4]int32{_CTRL_KERN, _KERN_PROC, _KERN_PROC_ALL, 0}
+// darwinKinfoProcs - `struct kinfo_proc` of the processes `op` and `arg` select, all or one PID
+func darwinKinfoProcs(op int32, arg int32) ([]*kinfoProc, error) {
+	mib := [4]int32{_CTRL_KERN, _KERN_PROC, op, arg}
 	size := uintptr(0)
 
 	_, _, errno := syscall.Syscall6(
@@ -73,6 +155,10 @@ func darwinSyscall() (*bytes.Buffer, error) {
 		return nil, errno
 	}
 
+	if size == 0 {
+		return []*kinfoProc{}, nil
+	}
+
 	bs := make([]byte, size)
 	_, _, errno = syscall.Syscall6(
 		syscall.SYS___SYSCTL,
@@ -87,22 +173,140 @@ func darwinSyscall() (*bytes.Buffer, error) {
 		return nil, errno
 	}
 
-	return bytes.NewBuffer(bs[0:size]), nil
+	procs := make([]*kinfoProc, 0, size/_KINFO_STRUCT_SIZE)
+	for i := uintptr(0); i+_KINFO_STRUCT_SIZE <= size; i += _KINFO_STRUCT_SIZE {
+		proc := &kinfoProc{}
+		if err := binary.Read(bytes.NewReader(bs[i:i+_KINFO_STRUCT_SIZE]), binary.LittleEndian, proc); err != nil {
+			return nil, err
+		}
+
+		procs = append(procs, proc)
+	}
+
+	return procs, nil
 }
 
 const (
 	_CTRL_KERN         = 1
 	_KERN_PROC         = 14
 	_KERN_PROC_ALL     = 0
+	_KERN_PROC_PID     = 1
 	_KINFO_STRUCT_SIZE = 648
 )
 
+// kinfoProc - the fields we use from `struct kinfo_proc` (sys/sysctl.h), `kp_proc` then `kp_eproc` at 296
 type kinfoProc struct {
-	_    [40]byte
-	Pid  int32
-	_    [199]byte
-	Comm [16]byte
-	_    [301]byte
-	PPid int32
-	_    [84]byte
+	StartSec  int64 // p_starttime.tv_sec
+	StartUsec int32 // p_starttime.tv_usec
+	_         [20]byte
+	Flag      int32
+	Stat      int8 // p_stat, one of SIDL, SRUN, SSLEEP, SSTOP, SZOMB
+	_         [3]byte
+	Pid       int32
+	_         [199]byte
+	Comm      [16]byte
+	_         [133]byte
+	Ruid      uint32 // e_pcred.p_ruid
+	Svuid     uint32 // e_pcred.p_svuid
+	Rgid      uint32 // e_pcred.p_rgid
+	Svgid     uint32 // e_pcred.p_svgid
+	_         [12]byte
+	UID       uint32 // e_ucred.cr_uid, the effective user
+	Ngroups   int16  // e_ucred.cr_ngroups
+	_         [2]byte
+	Groups    [16]uint32 // e_ucred.cr_groups, the first one is the effective group
+	_         [68]byte
+	PPid      int32
+	Pgid      int32 // e_pgid
+	_         [4]byte
+	Tdev      int32 // e_tdev, NODEV (-1) without a terminal
+	_         [72]byte
+}
+
+// darwinProcessState - SSTOP (4) is a process stopped by a signal, everything else counts as running
+func darwinProcessState(stat int8) contracts.ProcessState {
+	const sstop = 4
+	if stat == sstop {
+		return contracts.ProcessStateTraced
+	}
+
+	return contracts.ProcessStateRunning
+}
+
+const (
+	_PROC_INFO_CALL_PIDRUSAGE = 9
+	_RUSAGE_INFO_V2           = 2
+)
+
+// rusageInfoV2 - copied from sys/resource.h
+type rusageInfoV2 struct {
+	UUID                [16]byte
+	UserTime            uint64
+	SystemTime          uint64
+	PkgIdleWkups        uint64
+	InterruptWkups      uint64
+	Pageins             uint64
+	WiredSize           uint64
+	ResidentSize        uint64
+	PhysFootprint       uint64
+	ProcStartAbstime    uint64
+	ProcExitAbstime     uint64
+	ChildUserTime       uint64
+	ChildSystemTime     uint64
+	ChildPkgIdleWkups   uint64
+	ChildInterruptWkups uint64
+	ChildPageins        uint64
+	ChildElapsedAbstime uint64
+	DiskioBytesread     uint64
+	DiskioByteswritten  uint64
+}
+
+// darwinPidRusage - same as `proc_pid_rusage()`, fails with EPERM for processes of other users
+func darwinPidRusage(pid int) (rusageInfoV2, error) {
+	var info rusageInfoV2
+
+	_, _, errno := syscall.Syscall6(
+		syscall.SYS_PROC_INFO,
+		_PROC_INFO_CALL_PIDRUSAGE,
+		uintptr(pid),
+		_RUSAGE_INFO_V2,
+		0,
+		uintptr(unsafe.Pointer(&info)),
+		0)
+
+	if errno != 0 {
+		return info, errno
+	}
+
+	return info, nil
+}
+
+var (
+	machTimebaseSync      = &sync.Once{}
+	machTimebaseFrequency = uint64(0)
+)
+
+// darwinMachTimeToNanoseconds - the rusage times are in Mach absolute time units, `mach_timebase_info()`
+// scales them to nanoseconds by numer/denom, 1/1 on Intel and 125/3 on Apple silicon today, `hw.tbfrequency`
+// is the same ratio as units per second and needs no cgo
+func darwinMachTimeToNanoseconds(machTime uint64) uint64 {
+	machTimebaseSync.Do(func() {
+		frequency, err := unix.SysctlUint64("hw.tbfrequency")
+		if err != nil {
+			frequency32, _ := unix.SysctlUint32("hw.tbfrequency")
+			frequency = uint64(frequency32)
+		}
+
+		if frequency == 0 {
+			frequency = 1000000000
+			if runtime.GOARCH == "arm64" {
+				frequency = 24000000
+			}
+		}
+
+		machTimebaseFrequency = frequency
+	})
+
+	// split, so large times don't overflow
+	return machTime/machTimebaseFrequency*1000000000 + machTime%machTimebaseFrequency*1000000000/machTimebaseFrequency
 }
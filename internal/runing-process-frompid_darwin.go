@@ -37,17 +37,19 @@ func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
 	return results, nil
 }
 
+// getRuntimeProcessByPID - reads the one process from `kern.proc.pid.<pid>`, not the whole table
 func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
-	rps, err := listAllRuntimeProcesses()
+	procs, err := darwinKinfoProcs(_KERN_PROC_PID, int32(pid))
 	if err != nil {
 		return contracts.RuntimeProcess{}, err
 	}
 
-	for _, rp := range rps {
-		if rp.ProcessID == pid {
-			return rp, nil
-		}
+	if len(procs) == 0 || int(procs[0].Pid) != pid {
+		return contracts.RuntimeProcess{}, nil
 	}
 
-	return contracts.RuntimeProcess{}, nil
+	rp := runtimeProcessFromKinfoProc(procs[0])
+	readDarwinProcessDetails(&rp)
+
+	return rp, nil
 }
@@ -5,11 +5,15 @@ package internal
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"os"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
 )
 
 // copied from sys/sysctl.h
@@ -104,51 +108,155 @@ type Kinfo_proc struct {
 	Ki_tdflags      int64
 }
 
-func existingUnixProcessByPID(pid int) (contracts.RuningProcess, error) {
-	upm, err := fetchUnixProcMetadata(pid)
-	if err != nil {
-		return NewEmptyRuningProcess(), err
+func runtimeProcessFromKinfoProc(k *Kinfo_proc) contracts.RuntimeProcess {
+	ppid, pgid, sid, comm := copy_params(k)
+
+	// Ki_start is a `struct timeval`
+	startSec := int64(binary.LittleEndian.Uint64(k.Ki_start[0:8]))
+	startUsec := int64(binary.LittleEndian.Uint64(k.Ki_start[8:16]))
+
+	rusage := parseRusage(k.Ki_rusage)
+
+	rp := contracts.RuntimeProcess{
+		Executable:      comm,
+		ExecutableName:  comm,
+		Args:            []string{},
+		Environment:     []string{},
+		ProcessID:       int(k.Ki_pid),
+		ParentProcessID: ppid,
+		UserID:          int(k.Ki_ruid),
+		GroupID:         int(k.Ki_rgid),
+		SessionID:       sid,
+		ProcessGroupID:  pgid,
+		State:           processStateFromKiStat(k.Ki_stat[0]),
+		StartTime:       time.Unix(startSec, startUsec*1000),
+		Identity:        processIdentity(freebsdBootID(), int(k.Ki_pid), uint64(startSec*1000000+startUsec)),
+		IO: contracts.ProcessIO{
+			BlocksAvailable: true,
+			ReadBlocks:      rusage.Inblock,
+			WriteBlocks:     rusage.Oublock,
+		},
+		CPU: contracts.ProcessCPU{
+			UserTicks:                   rusage.UtimeUsec,
+			SystemTicks:                 rusage.StimeUsec,
+			TicksPerSecond:              1000000,
+			NumThreads:                  int(k.Ki_numthreads),
+			VoluntaryContextSwitches:    rusage.Nvcsw,
+			NonVoluntaryContextSwitches: rusage.Nivcsw,
+		},
+		Memory: contracts.ProcessMemory{
+			VirtualSize:      uint64(k.Ki_size),
+			ResidentSize:     uint64(k.Ki_rssize) * uint64(os.Getpagesize()),
+			PeakResidentSize: rusage.Maxrss * 1024,
+		},
+		Credentials: credentialsFromKinfoProc(k),
 	}
 
-	osProcess, err := os.FindProcess(pid)
-	if err != nil {
-		return NewEmptyRuningProcess(), err
+	// NODEV
+	if k.Ki_tdev != -1 {
+		rp.TTY = ttyNameFromDevice(uint64(uint32(k.Ki_tdev)))
 	}
 
-	return NewRuningProcessWithOSProc(
-		contracts.ProcessTemplate{
-			Executable: upm.Executable,
-		},
-		osProcess,
-	), nil
+	readProcessScheduling(rp.ProcessID, &rp)
+
+	return rp
 }
 
-func fetchUnixProcMetadata(pid int) (unixProcMedata, error) {
-	mib := []int32{CTL_KERN, KERN_PROC, KERN_PROC_PID, int32(pid)}
+// credentialsFromKinfoProc - `ki_groups[0]` is the effective group, FreeBSD has no filesystem IDs
+func credentialsFromKinfoProc(k *Kinfo_proc) contracts.ProcessCredentials {
+	ngroups := int(binary.LittleEndian.Uint16(k.Ki_ngroups[:]))
+	if ngroups > len(k.Ki_groups)/4 {
+		ngroups = len(k.Ki_groups) / 4
+	}
 
-	buf, length, err := call_syscall(mib)
-	if err != nil {
-		return unixProcMedata{}, err
+	groups := make([]int, 0, ngroups)
+	for i := 0; i < ngroups; i++ {
+		groups = append(groups, int(int32(binary.LittleEndian.Uint32(k.Ki_groups[i*4:]))))
 	}
 
-	proc_k := Kinfo_proc{}
-	if length != uint64(unsafe.Sizeof(proc_k)) {
-		return unixProcMedata{}, err
+	effectiveGroupID := int(k.Ki_rgid)
+	if len(groups) > 0 {
+		effectiveGroupID = groups[0]
 	}
 
-	k, err := parse_kinfo_proc(buf)
-	if err != nil {
-		return unixProcMedata{}, err
+	credentials := contracts.ProcessCredentials{
+		RealUserID:            int(k.Ki_ruid),
+		EffectiveUserID:       int(k.Ki_uid),
+		SavedUserID:           int(k.Ki_svuid),
+		FilesystemUserID:      int(k.Ki_uid),
+		RealGroupID:           int(k.Ki_rgid),
+		EffectiveGroupID:      effectiveGroupID,
+		SavedGroupID:          int(k.Ki_svgid),
+		FilesystemGroupID:     effectiveGroupID,
+		SupplementaryGroupIDs: groups,
+	}
+	resolveCredentialNames(&credentials)
+
+	return credentials
+}
+
+// freebsdRusage - the parts of `struct rusage` we use, copied from sys/resource.h
+type freebsdRusage struct {
+	UtimeUsec uint64
+	StimeUsec uint64
+	Maxrss    uint64 // KB
+	Inblock   uint64
+	Oublock   uint64
+	Nvcsw     uint64
+	Nivcsw    uint64
+}
+
+func parseRusage(b [144]byte) freebsdRusage {
+	field := func(offset int) uint64 {
+		return binary.LittleEndian.Uint64(b[offset : offset+8])
 	}
 
-	ppid, pgrp, sid, binary := copy_params(&k)
+	return freebsdRusage{
+		UtimeUsec: field(0)*1000000 + field(8),
+		StimeUsec: field(16)*1000000 + field(24),
+		Maxrss:    field(32),
+		Inblock:   field(88),
+		Oublock:   field(96),
+		Nvcsw:     field(128),
+		Nivcsw:    field(136),
+	}
+}
+
+// processStateFromKiStat - maps `ki_stat`, copied from sys/proc.h
+func processStateFromKiStat(stat byte) contracts.ProcessState {
+	switch stat {
+	case 2: // SRUN
+		return contracts.ProcessStateRunning
+	case 3: // SSLEEP
+		return contracts.ProcessStateWaitingEvent
+	case 4: // SSTOP
+		return contracts.ProcessStateTraced
+	case 5: // SZOMB
+		return contracts.ProcessStateObsolete
+	case 1, 6, 7: // SIDL, SWAIT, SLOCK
+		return contracts.ProcessStateWaitingIO
+	}
+
+	return contracts.ProcessStateUnknown
+}
+
+var (
+	bootIDSync = &sync.Once{}
+	bootIDVal  = ""
+)
+
+// freebsdBootID - FreeBSD has no boot UUID, the boot time identifies the boot just as well
+func freebsdBootID() string {
+	bootIDSync.Do(func() {
+		boottime, err := unix.SysctlTimeval("kern.boottime")
+		if err != nil {
+			return
+		}
+
+		bootIDVal = fmt.Sprintf("%d.%06d", boottime.Sec, boottime.Usec)
+	})
 
-	return unixProcMedata{
-		ParentPID:  ppid,
-		Pgrp:       pgrp,
-		Sid:        sid,
-		Executable: binary,
-	}, nil
+	return bootIDVal
 }
 
 func copy_params(k *Kinfo_proc) (int, int, int, string) {
@@ -3,23 +3,13 @@
 package internal
 
 import (
+	"os"
 	"unsafe"
 
 	"github.com/remoteit/systemkit-processes/contracts"
 )
 
-func processByPID(pid int) (contracts.RuningProcess, error) {
-	mib := []int32{CTL_KERN, KERN_PROC, KERN_PROC_PATHNAME, int32(pid)}
-
-	_, _, err := call_syscall(mib)
-	if err != nil {
-		return nil, err
-	}
-
-	return existingUnixProcessByPID(pid)
-}
-
-func allProcesses() ([]contracts.RuningProcess, error) {
+func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
 	results := make([]contracts.RuningProcess, 0, 50)
 
 	mib := []int32{CTL_KERN, KERN_PROC, KERN_PROC_PROC, 0}
@@ -40,14 +30,51 @@ func allProcesses() ([]contracts.RuningProcess, error) {
 		if err != nil {
 			continue
 		}
-		p, err := existingUnixProcessByPID(int(k.Ki_pid))
+
+		rp := runtimeProcessFromKinfoProc(&k)
+
+		osProcess, err := os.FindProcess(rp.ProcessID)
 		if err != nil {
 			continue
 		}
-		// p.ppid, p.pgrp, p.sid, p.binary = copy_params(&k)
 
-		results = append(results, p)
+		results = append(results, NewRuningProcessWithOSProc(
+			contracts.ProcessTemplate{
+				Executable:       rp.Executable,
+				Args:             rp.Args,
+				WorkingDirectory: rp.WorkingDirectory,
+				Environment:      rp.Environment,
+			},
+			osProcess,
+		))
 	}
 
 	return results, nil
 }
+
+func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
+	mib := []int32{CTL_KERN, KERN_PROC, KERN_PROC_PID, int32(pid)}
+
+	buf, length, err := call_syscall(mib)
+	if err != nil {
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateUnknown,
+		}, err
+	}
+
+	proc_k := Kinfo_proc{}
+	if length != uint64(unsafe.Sizeof(proc_k)) {
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateNonExistent,
+		}, contracts.ErrProcessDoesNotExist
+	}
+
+	k, err := parse_kinfo_proc(buf)
+	if err != nil {
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateUnknown,
+		}, err
+	}
+
+	return runtimeProcessFromKinfoProc(&k), nil
+}
@@ -3,25 +3,79 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/remoteit/systemkit-processes/contracts"
 )
 
 func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
+	rps, _, err := snapshotRuntimeProcesses(contracts.ProcessFieldsAll, DefaultSnapshotWorkers())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]contracts.RuningProcess, 0, len(rps))
+	for _, rp := range rps {
+		p, err := NewRuningProcessFromRuntimeProcess(rp)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, p)
+	}
+
+	return results, nil
+}
+
+// snapshotRuntimeProcesses - reads `fields` of every PID in `/proc` in parallel, processes that
+// exit while being read are left out
+func snapshotRuntimeProcesses(fields contracts.ProcessFields, workers int) ([]contracts.RuntimeProcess, []contracts.ProcessFields, error) {
+	pids, err := listProcessIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Ints(pids)
+
+	rps := make([]contracts.RuntimeProcess, len(pids))
+	loaded := make([]contracts.ProcessFields, len(pids))
+	for i, pid := range pids {
+		rps[i].ProcessID = pid
+	}
+
+	// status is always read, it's the cheapest way to tell the PID still exists
+	ReadRuntimeProcessesFields(rps, loaded, fields|contracts.ProcessFieldStatus, workers)
+
+	results := rps[:0]
+	resultsLoaded := loaded[:0]
+	for i := range rps {
+		if rps[i].State == contracts.ProcessStateNonExistent {
+			continue
+		}
+
+		results = append(results, rps[i])
+		resultsLoaded = append(resultsLoaded, loaded[i])
+	}
+
+	return results, resultsLoaded, nil
+}
+
+func listProcessIDs() ([]int, error) {
 	d, err := os.Open("/proc")
 	if err != nil {
 		return nil, err
 	}
 	defer d.Close()
 
-	results := []contracts.RuningProcess{}
+	results := []int{}
 	for {
 		fis, err := d.Readdir(10)
 		if err == io.EOF {
@@ -43,19 +97,12 @@ func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
 				continue
 			}
 
-			// From this point forward, any errors we just ignore, because
-			// it might simply be that the process doesn't exist anymore.
 			pid, err := strconv.ParseInt(name, 10, 0)
 			if err != nil {
 				continue
 			}
 
-			p, err := getRuningProcessByPID(int(pid))
-			if err != nil {
-				continue
-			}
-
-			results = append(results, p)
+			results = append(results, int(pid))
 		}
 	}
 
@@ -63,82 +110,154 @@ func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
 }
 
 func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
+	procMedata := contracts.RuntimeProcess{}
+	_, err := readRuntimeProcessFields(pid, &procMedata, contracts.ProcessFieldsAll)
+
+	return procMedata, err
+}
+
+// readRuntimeProcessFields - reads only the files backing `fields` into `procMedata`,
+// call it again with other fields to complete a partially read process
+func readRuntimeProcessFields(pid int, procMedata *contracts.RuntimeProcess, fields contracts.ProcessFields) (contracts.ProcessFields, error) {
 	folder := fmt.Sprintf("/proc/%d", pid)
 
 	// 1 - check folder exists
 	_, err := os.Stat(folder)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return contracts.RuntimeProcess{
-				State: contracts.ProcessStateNonExistent,
-			}, contracts.ErrProcessDoesNotExist
+			procMedata.State = contracts.ProcessStateNonExistent
+			return 0, contracts.ErrProcessDoesNotExist
 		}
 
-		return contracts.RuntimeProcess{
-			State: contracts.ProcessStateUnknown,
-		}, err
+		procMedata.State = contracts.ProcessStateUnknown
+		return 0, err
 	}
 
 	//
 	// /proc/%d/*
 	// 		cwd			-> sym link to the working dir
 	//		environ		-> env vars
-	//		status 		-> Name, Pid, PPid, Uid, Gid
+	//		status 		-> Name, Pid, PPid, Uid, Gid, Groups, NSpid, Cap*, Seccomp, NoNewPrivs, VmRSS, VmHWM, VmSwap, context switches
 	//		cmdline		-> full path with args
+	//		stat		-> process group, session, tty, start time, utime, stime, priority, nice, num_threads, vsize, rss, rt_priority, policy
+	//		oom_score_adj	-> OOM killer adjustment
+	//		io			-> rchar, wchar, syscr, syscw, read_bytes, write_bytes, cancelled_write_bytes
+	//		ns/*		-> sym links to the namespaces, like `pid:[4026531836]`
+	//		cgroup		-> cgroup paths, the container ID is in there
 	//
 	// 		comm		-> executable name
 	//		loginuid 	-> ID of the running-as user
 	//
 
-	procMedata := contracts.RuntimeProcess{
-		Args:        []string{},
-		Environment: []string{},
-		State:       contracts.ProcessStateRunning,
+	procMedata.ProcessID = pid
+	if procMedata.State == contracts.ProcessStateNonExistent {
+		procMedata.State = contracts.ProcessStateRunning
+	}
+	if procMedata.Args == nil {
+		procMedata.Args = []string{}
+	}
+	if procMedata.Environment == nil {
+		procMedata.Environment = []string{}
 	}
 
 	// 2 - read cwd
-	fi, err := os.Lstat(path.Join(folder, "cwd"))
-	if err == nil && fi != nil && fi.Mode()&os.ModeSymlink != 0 {
-		procMedata.WorkingDirectory, _ = os.Readlink(path.Join(folder, "cwd"))
+	if fields&contracts.ProcessFieldWorkingDirectory != 0 {
+		fi, err := os.Lstat(path.Join(folder, "cwd"))
+		if err == nil && fi != nil && fi.Mode()&os.ModeSymlink != 0 {
+			procMedata.WorkingDirectory, _ = os.Readlink(path.Join(folder, "cwd"))
+		}
 	}
 
 	// 3 - read environ
-	data, _ := ioutil.ReadFile(path.Join(folder, "environ"))
-	lines := strings.Split(string(data), "\x00")
-	for _, line := range lines {
-		procMedata.Environment = append(procMedata.Environment, line)
+	if fields&contracts.ProcessFieldEnvironment != 0 {
+		data, _ := ioutil.ReadFile(path.Join(folder, "environ"))
+		lines := strings.Split(string(data), "\x00")
+		for _, line := range lines {
+			procMedata.Environment = append(procMedata.Environment, line)
+		}
 	}
 
 	// 4 - read status
-	data, _ = ioutil.ReadFile(path.Join(folder, "status"))
-	lines = strings.Split(string(data), "\n")
+	if fields&contracts.ProcessFieldStatus != 0 {
+		data, statusErr := ioutil.ReadFile(path.Join(folder, "status"))
+		if isProcessGoneErr(statusErr) {
+			procMedata.State = contracts.ProcessStateNonExistent
+			return 0, contracts.ErrProcessDoesNotExist
+		}
+
+		readProcStatus(string(data), procMedata)
+		resolveCredentialNames(&procMedata.Credentials)
+	}
+
+	// 5 - read stat
+	if fields&contracts.ProcessFieldStat != 0 {
+		statFields, statErr := readProcStatFields(pid)
+		if isProcessGoneErr(statErr) {
+			procMedata.State = contracts.ProcessStateNonExistent
+			return 0, contracts.ErrProcessDoesNotExist
+		}
+
+		if statErr == nil {
+			readProcStat(pid, statFields, procMedata)
+			readProcScheduling(pid, folder, statFields, procMedata)
+		}
+	}
+
+	// 6 - read io, only readable by the owner
+	if fields&contracts.ProcessFieldIO != 0 {
+		data, ioErr := ioutil.ReadFile(path.Join(folder, "io"))
+		procMedata.IO.Available = ioErr == nil
+		readProcIO(string(data), procMedata)
+	}
+
+	// 7 - read namespaces and cgroup
+	if fields&contracts.ProcessFieldNamespaces != 0 {
+		procMedata.Namespaces = readProcNamespaces(folder)
+
+		data, _ := ioutil.ReadFile(path.Join(folder, "cgroup"))
+		procMedata.Container = ContainerFromProcCgroup(string(data))
+	}
+
+	// 8 - read cmdline
+	if fields&contracts.ProcessFieldCommandLine != 0 {
+		data, _ := ioutil.ReadFile(path.Join(folder, "cmdline"))
+		lines := strings.Split(string(data), "\x00")
+		for index, line := range lines {
+			if index == 0 {
+				procMedata.Executable = line
+			} else {
+				trimmedLine := strings.TrimSpace(line)
+				if len(trimmedLine) > 0 {
+					procMedata.Args = append(procMedata.Args, trimmedLine)
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// isProcessGoneErr - the process exited after its folder was found, its files are gone, or fail
+// with ESRCH while the folder is still being torn down
+func isProcessGoneErr(err error) bool {
+	return err != nil && (os.IsNotExist(err) || errors.Is(err, syscall.ESRCH))
+}
+
+// readProcStatus - parses `/proc/<pid>/status`
+func readProcStatus(data string, procMedata *contracts.RuntimeProcess) {
+	lines := strings.Split(data, "\n")
 	for _, line := range lines {
-		props := strings.Split(line, ":")
+		props := strings.SplitN(line, ":", 2)
 		if len(props) > 1 {
 			key := strings.TrimSpace(strings.ToLower(props[0]))
 			val := strings.TrimSpace(strings.ToLower(props[1]))
 			switch key {
 			case "name":
-				procMedata.ExecutableName = val
+				procMedata.ExecutableName = strings.TrimSpace(props[1]) // keep the case, names are matched on
 			case "state":
 				states := strings.Split(val, " ")
 				if len(states) > 0 {
-					switch states[0] {
-					case "d":
-						procMedata.State = contracts.ProcessStateWaitingIO
-					case "r":
-						procMedata.State = contracts.ProcessStateRunning
-					case "s":
-						procMedata.State = contracts.ProcessStateWaitingEvent
-					case "t":
-						procMedata.State = contracts.ProcessStateTraced
-					case "w":
-						procMedata.State = contracts.ProcessStatePaging
-					case "x":
-						procMedata.State = contracts.ProcessStateDead
-					case "z":
-						procMedata.State = contracts.ProcessStateObsolete
-					}
+					procMedata.State = processStateFromProcLetter(states[0], procMedata.State)
 				}
 			case "pid":
 				fetchedPid, _ := strconv.Atoi(val)
@@ -147,34 +266,179 @@ func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
 				ppid, _ := strconv.Atoi(val)
 				procMedata.ParentProcessID = ppid
 			case "uid":
-				uids := strings.Split(val, "\t")
-				if len(uids) > 0 {
-					uid, _ := strconv.Atoi(uids[0])
-					procMedata.UserID = uid
-				}
+				// real, effective, saved, filesystem
+				uids := parseProcStatusIDs(val)
+				procMedata.Credentials.RealUserID = uids[0]
+				procMedata.Credentials.EffectiveUserID = uids[1]
+				procMedata.Credentials.SavedUserID = uids[2]
+				procMedata.Credentials.FilesystemUserID = uids[3]
+				procMedata.UserID = uids[0]
 			case "gid":
-				gids := strings.Split(val, "\t")
-				if len(gids) > 0 {
-					gid, _ := strconv.Atoi(gids[0])
-					procMedata.GroupID = gid
+				gids := parseProcStatusIDs(val)
+				procMedata.Credentials.RealGroupID = gids[0]
+				procMedata.Credentials.EffectiveGroupID = gids[1]
+				procMedata.Credentials.SavedGroupID = gids[2]
+				procMedata.Credentials.FilesystemGroupID = gids[3]
+				procMedata.GroupID = gids[0]
+			case "groups":
+				procMedata.Credentials.SupplementaryGroupIDs = []int{}
+				for _, group := range strings.Fields(val) {
+					gid, _ := strconv.Atoi(group)
+					procMedata.Credentials.SupplementaryGroupIDs = append(procMedata.Credentials.SupplementaryGroupIDs, gid)
+				}
+			case "nspid":
+				procMedata.NamespacePIDs = []int{}
+				for _, nspid := range strings.Fields(val) {
+					pid, _ := strconv.Atoi(nspid)
+					procMedata.NamespacePIDs = append(procMedata.NamespacePIDs, pid)
+				}
+			case "capinh":
+				procMedata.Security.InheritableCapabilities = capabilityNamesFromMask(val)
+			case "capprm":
+				procMedata.Security.PermittedCapabilities = capabilityNamesFromMask(val)
+			case "capeff":
+				procMedata.Security.EffectiveCapabilities = capabilityNamesFromMask(val)
+			case "capbnd":
+				procMedata.Security.BoundingCapabilities = capabilityNamesFromMask(val)
+			case "capamb":
+				procMedata.Security.AmbientCapabilities = capabilityNamesFromMask(val)
+			case "seccomp":
+				switch val {
+				case "0":
+					procMedata.Security.SeccompMode = contracts.SeccompModeDisabled
+				case "1":
+					procMedata.Security.SeccompMode = contracts.SeccompModeStrict
+				case "2":
+					procMedata.Security.SeccompMode = contracts.SeccompModeFilter
 				}
+			case "nonewprivs":
+				procMedata.Security.NoNewPrivileges = val == "1"
+			case "vmrss":
+				procMedata.Memory.ResidentSize = parseProcStatusKB(val)
+			case "vmhwm":
+				procMedata.Memory.PeakResidentSize = parseProcStatusKB(val)
+			case "vmswap":
+				procMedata.Memory.SwapSize = parseProcStatusKB(val)
+			case "voluntary_ctxt_switches":
+				procMedata.CPU.VoluntaryContextSwitches, _ = strconv.ParseUint(val, 10, 64)
+			case "nonvoluntary_ctxt_switches":
+				procMedata.CPU.NonVoluntaryContextSwitches, _ = strconv.ParseUint(val, 10, 64)
 			}
 		}
 	}
+}
+
+// parseProcStatusIDs - parses the real, effective, saved and filesystem IDs of a `Uid` or `Gid` line
+func parseProcStatusIDs(val string) [4]int {
+	ids := [4]int{}
+	for i, field := range strings.Fields(val) {
+		if i >= len(ids) {
+			break
+		}
+		ids[i], _ = strconv.Atoi(field)
+	}
 
-	// 4 - read cmdline
-	data, _ = ioutil.ReadFile(path.Join(folder, "cmdline"))
-	lines = strings.Split(string(data), "\x00")
-	for index, line := range lines {
-		if index == 0 {
-			procMedata.Executable = line
-		} else {
-			trimmedLine := strings.TrimSpace(line)
-			if len(trimmedLine) > 0 {
-				procMedata.Args = append(procMedata.Args, trimmedLine)
+	return ids
+}
+
+// readProcStat - parses the fields returned by `readProcStatFields()`
+func readProcStat(pid int, statFields []string, procMedata *contracts.RuntimeProcess) {
+	procMedata.State = processStateFromProcLetter(procStatField(statFields, 3), procMedata.State)
+	procMedata.ProcessGroupID, _ = strconv.Atoi(procStatField(statFields, 5))
+	procMedata.SessionID, _ = strconv.Atoi(procStatField(statFields, 6))
+
+	ttyNr, _ := strconv.ParseUint(procStatField(statFields, 7), 10, 32)
+	procMedata.TTY = ttyNameFromProcTTYNr(uint32(ttyNr))
+
+	startTicks, _ := strconv.ParseUint(procStatField(statFields, 22), 10, 64)
+	procMedata.StartTime = bootTime().Add(ticksToDuration(startTicks))
+	procMedata.Identity = processIdentity(bootID(), pid, startTicks)
+
+	procMedata.CPU.UserTicks, _ = strconv.ParseUint(procStatField(statFields, 14), 10, 64)
+	procMedata.CPU.SystemTicks, _ = strconv.ParseUint(procStatField(statFields, 15), 10, 64)
+	procMedata.CPU.TicksPerSecond = clockTicksPerSecond
+	procMedata.CPU.Priority, _ = strconv.Atoi(procStatField(statFields, 18))
+	procMedata.CPU.Nice, _ = strconv.Atoi(procStatField(statFields, 19))
+	procMedata.CPU.NumThreads, _ = strconv.Atoi(procStatField(statFields, 20))
+	procMedata.Memory.VirtualSize, _ = strconv.ParseUint(procStatField(statFields, 23), 10, 64)
+
+	// `status` has no Vm* lines for kernel threads
+	if procMedata.Memory.ResidentSize == 0 {
+		rssPages, _ := strconv.ParseUint(procStatField(statFields, 24), 10, 64)
+		procMedata.Memory.ResidentSize = rssPages * uint64(os.Getpagesize())
+	}
+}
+
+// readProcNamespaces - reads the inode numbers of the `/proc/<pid>/ns/*` links, needs the same
+// permissions as ptrace, so only the ones that can be read are set
+func readProcNamespaces(folder string) contracts.ProcessNamespaces {
+	namespaces := contracts.ProcessNamespaces{}
+	for name, inode := range map[string]*uint64{
+		"cgroup": &namespaces.Cgroup,
+		"ipc":    &namespaces.IPC,
+		"mnt":    &namespaces.Mount,
+		"net":    &namespaces.Net,
+		"pid":    &namespaces.PID,
+		"time":   &namespaces.Time,
+		"user":   &namespaces.User,
+		"uts":    &namespaces.UTS,
+	} {
+		link, err := os.Readlink(path.Join(folder, "ns", name))
+		if err != nil {
+			continue
+		}
+
+		*inode = parseBracketedInode(link)
+	}
+
+	return namespaces
+}
+
+// readProcIO - parses `/proc/<pid>/io`
+func readProcIO(data string, procMedata *contracts.RuntimeProcess) {
+	lines := strings.Split(data, "\n")
+	for _, line := range lines {
+		props := strings.Split(line, ":")
+		if len(props) > 1 {
+			val, _ := strconv.ParseUint(strings.TrimSpace(props[1]), 10, 64)
+			switch strings.TrimSpace(props[0]) {
+			case "rchar":
+				procMedata.IO.ReadChars = val
+			case "wchar":
+				procMedata.IO.WriteChars = val
+			case "syscr":
+				procMedata.IO.ReadSyscalls = val
+			case "syscw":
+				procMedata.IO.WriteSyscalls = val
+			case "read_bytes":
+				procMedata.IO.ReadBytes = val
+			case "write_bytes":
+				procMedata.IO.WriteBytes = val
+			case "cancelled_write_bytes":
+				procMedata.IO.CancelledWriteBytes = val
 			}
 		}
 	}
+}
 
-	return procMedata, err
+// processStateFromProcLetter - maps the state letter from `status` or `stat`, unknown letters keep `current`
+func processStateFromProcLetter(letter string, current contracts.ProcessState) contracts.ProcessState {
+	switch strings.ToLower(letter) {
+	case "d":
+		return contracts.ProcessStateWaitingIO
+	case "r":
+		return contracts.ProcessStateRunning
+	case "s":
+		return contracts.ProcessStateWaitingEvent
+	case "t":
+		return contracts.ProcessStateTraced
+	case "w":
+		return contracts.ProcessStatePaging
+	case "x":
+		return contracts.ProcessStateDead
+	case "z":
+		return contracts.ProcessStateObsolete
+	}
+
+	return current
 }
@@ -5,7 +5,13 @@ package internal
 import (
 	"encoding/binary"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
 )
 
 type ushort_t uint16
@@ -66,26 +72,131 @@ type psinfo_t struct {
 	Pr_lwp      [128]byte /* information for representative lwp */
 }
 
-func fetchUnixProcMetadata(pid int) (unixProcMedata, error) {
+func readPsinfo(pid int) (psinfo_t, error) {
 	path := fmt.Sprintf("/proc/%d/psinfo", pid)
 	fh, err := os.Open(path)
 	if err != nil {
-		return unixProcMedata{}, err
+		return psinfo_t{}, err
 	}
 	defer fh.Close()
 
 	var psinfo psinfo_t
 	err = binary.Read(fh, binary.LittleEndian, &psinfo)
 	if err != nil {
-		return unixProcMedata{}, err
+		return psinfo_t{}, err
+	}
+
+	return psinfo, nil
+}
+
+func runtimeProcessFromPsinfo(psinfo *psinfo_t) contracts.RuntimeProcess {
+	executable := toString(psinfo.Pr_fname[:], 16)
+	startSec, startNsec := timestrucToUnix(psinfo.Pr_start)
+	cpuSec, cpuNsec := timestrucToUnix(psinfo.Pr_time)
+
+	state := contracts.ProcessStateRunning
+	if psinfo.Pr_nlwp == 0 {
+		state = contracts.ProcessStateObsolete
+	} else if psinfo.Pr_lwp[26] == 'T' {
+		// pr_sname of the representative lwpsinfo_t, 'T' when stopped
+		state = contracts.ProcessStateTraced
+	}
+
+	rp := contracts.RuntimeProcess{
+		Executable:      executable,
+		ExecutableName:  executable,
+		Args:            strings.Fields(toString(psinfo.Pr_psargs[:], 80)),
+		Environment:     []string{},
+		ProcessID:       int(psinfo.Pr_pid),
+		ParentProcessID: int(psinfo.Pr_ppid),
+		UserID:          int(psinfo.Pr_uid),
+		GroupID:         int(psinfo.Pr_gid),
+		SessionID:       int(psinfo.Pr_sid),
+		ProcessGroupID:  int(psinfo.Pr_pgid),
+		State:           state,
+		StartTime:       time.Unix(startSec, startNsec),
+		Identity:        processIdentity(solarisBootID(), int(psinfo.Pr_pid), uint64(startSec)*1000000000+uint64(startNsec)),
+		// psinfo only has user and system time combined
+		CPU: contracts.ProcessCPU{
+			UserTicks:      uint64(cpuSec)*1000000000 + uint64(cpuNsec),
+			TicksPerSecond: 1000000000,
+			NumThreads:     int(psinfo.Pr_nlwp),
+		},
+		Memory: contracts.ProcessMemory{
+			VirtualSize:  uint64(psinfo.Pr_size) * 1024,
+			ResidentSize: uint64(psinfo.Pr_rssize) * 1024,
+		},
+		Credentials: contracts.ProcessCredentials{
+			RealUserID:        int(psinfo.Pr_uid),
+			EffectiveUserID:   int(psinfo.Pr_euid),
+			SavedUserID:       int(psinfo.Pr_euid),
+			FilesystemUserID:  int(psinfo.Pr_euid),
+			RealGroupID:       int(psinfo.Pr_gid),
+			EffectiveGroupID:  int(psinfo.Pr_egid),
+			SavedGroupID:      int(psinfo.Pr_egid),
+			FilesystemGroupID: int(psinfo.Pr_egid),
+		},
 	}
+	readPrcred(int(psinfo.Pr_pid), &rp.Credentials)
+	resolveCredentialNames(&rp.Credentials)
 
-	result := unixProcMedata{
-		ParentPID:  int(psinfo.Pr_ppid),
-		Executable: toString(psinfo.Pr_fname[:], 16),
+	// PRNODEV
+	if psinfo.Pr_ttydev != dev_t(^uint64(0)) {
+		rp.TTY = ttyNameFromDevice(uint64(psinfo.Pr_ttydev))
 	}
 
-	return result, nil
+	readProcessScheduling(rp.ProcessID, &rp)
+
+	return rp
+}
+
+// readPrcred - reads saved IDs and groups from `/proc/<pid>/cred`, a `prcred_t` followed by the groups,
+// only readable by the owner, psinfo has the rest
+func readPrcred(pid int, credentials *contracts.ProcessCredentials) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cred", pid))
+	if err != nil || len(data) < 28 {
+		return
+	}
+
+	field := func(index int) int {
+		return int(int32(binary.LittleEndian.Uint32(data[index*4:])))
+	}
+
+	// pr_euid, pr_ruid, pr_suid, pr_egid, pr_rgid, pr_sgid, pr_ngroups, pr_groups[]
+	credentials.SavedUserID = field(2)
+	credentials.SavedGroupID = field(5)
+
+	ngroups := field(6)
+	credentials.SupplementaryGroupIDs = []int{}
+	for i := 0; i < ngroups && 28+(i+1)*4 <= len(data); i++ {
+		credentials.SupplementaryGroupIDs = append(credentials.SupplementaryGroupIDs, field(7+i))
+	}
+}
+
+// timestrucToUnix - decodes a `timestruc_t` into seconds and nanoseconds
+func timestrucToUnix(ts timestruc_t) (int64, int64) {
+	return int64(binary.LittleEndian.Uint64(ts[0:8])), int64(binary.LittleEndian.Uint64(ts[8:16]))
+}
+
+var (
+	bootIDSync = &sync.Once{}
+	bootIDVal  = ""
+)
+
+// solarisBootID - the start time of `sched` (PID 0) is the boot time
+func solarisBootID() string {
+	bootIDSync.Do(func() {
+		psinfo, err := readPsinfo(0)
+		if err != nil {
+			return
+		}
+
+		startSec, startNsec := timestrucToUnix(psinfo.Pr_start)
+
+		bootIDVal = fmt.Sprintf("%d.%09d", startSec, startNsec)
+	})
+
+	return bootIDVal
 }
 
 func toString(array []byte, len int) string {
@@ -2,12 +2,64 @@
 
 package internal
 
-import "github.com/remoteit/systemkit-processes/contracts"
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
 
 func getAllRuningProcesses() ([]contracts.RuningProcess, error) {
-	// FIXME:
+	fis, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	results := []contracts.RuningProcess{}
+	for _, fi := range fis {
+		pid, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+
+		rp, err := getRuntimeProcessByPID(pid)
+		if err != nil {
+			continue
+		}
+
+		osProcess, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, NewRuningProcessWithOSProc(
+			contracts.ProcessTemplate{
+				Executable:       rp.Executable,
+				Args:             rp.Args,
+				WorkingDirectory: rp.WorkingDirectory,
+				Environment:      rp.Environment,
+			},
+			osProcess,
+		))
+	}
+
+	return results, nil
 }
 
 func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
-	// FIXME:
+	psinfo, err := readPsinfo(pid)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return contracts.RuntimeProcess{
+				State: contracts.ProcessStateNonExistent,
+			}, contracts.ErrProcessDoesNotExist
+		}
+
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateUnknown,
+		}, err
+	}
+
+	return runtimeProcessFromPsinfo(&psinfo), nil
 }
@@ -5,6 +5,7 @@ package internal
 import (
 	"path/filepath"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -72,8 +73,9 @@ func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
 	for {
 		if processEntry.ProcessID == uint32(pid) {
 			executable := getExecutabe(&processEntry)
+			startTime := getProcessStartTime(pid)
 
-			return contracts.RuntimeProcess{
+			rp := contracts.RuntimeProcess{
 				Executable:       executable,
 				ExecutableName:   filepath.Base(executable),
 				Args:             []string{},
@@ -84,7 +86,16 @@ func getRuntimeProcessByPID(pid int) (contracts.RuntimeProcess, error) {
 				UserID:           0,
 				GroupID:          0,
 				State:            contracts.ProcessStateRunning,
-			}, nil
+				StartTime:        startTime,
+				Identity:         processIdentity("", pid, uint64(startTime.UnixNano())),
+			}
+
+			rp.CPU.NumThreads = int(processEntry.Threads)
+
+			readWindowsCredentials(pid, &rp)
+			readWindowsMetrics(pid, &rp)
+
+			return rp, nil
 		}
 
 		err = windows.Process32Next(handle, &processEntry)
@@ -109,3 +120,20 @@ func getExecutabe(processEntry *windows.ProcessEntry32) string {
 
 	return syscall.UTF16ToString(processEntry.ExeFile[:end])
 }
+
+// getProcessStartTime - the creation time is absolute on Windows, no boot ID is needed to make it unique
+func getProcessStartTime(pid int) time.Time {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return time.Unix(0, 0)
+	}
+	defer windows.CloseHandle(handle)
+
+	var creationTime, exitTime, kernelTime, userTime windows.Filetime
+	err = windows.GetProcessTimes(handle, &creationTime, &exitTime, &kernelTime, &userTime)
+	if err != nil {
+		return time.Unix(0, 0)
+	}
+
+	return time.Unix(0, creationTime.Nanoseconds())
+}
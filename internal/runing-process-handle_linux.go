@@ -0,0 +1,254 @@
+// +build linux
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+const processHandlePollInterval = 500 * time.Millisecond
+
+// processHandle - a stable reference to a process that survives PID reuse
+//
+// On kernels >= 5.3 it is backed by a pidfd, on older kernels (or when `pidfd_open()` is not
+// permitted) it falls back to the PID plus the start time captured when the handle was created.
+// Only pinned children keep their pidfd until `release()`, handles made from a PID open one for each
+// operation and close it right after, so a scan over thousands of processes holds no descriptors
+type processHandle struct {
+	pid              int
+	startTicks       uint64
+	pinned           bool
+	pidfd            *os.File
+	pidfdUsers       int // operations using the pinned pidfd, `release()` leaves closing it to the last one
+	pidfdUnavailable bool
+	gone             bool
+	sync             *sync.Mutex
+}
+
+// findProcess - an `os.Process` for `pid` that holds nothing open, the ones `os.FindProcess()` returns
+// keep a pidfd of their own with newer Go until they are collected, the handle opens one when it needs it
+func findProcess(pid int) (*os.Process, error) {
+	return &os.Process{Pid: pid}, nil
+}
+
+func newProcessHandle(osProcess *os.Process) *processHandle {
+	thisRef := &processHandle{
+		pid:  osProcess.Pid,
+		sync: &sync.Mutex{},
+	}
+
+	thisRef.startTicks, _ = readProcStartTicks(thisRef.pid)
+
+	return thisRef
+}
+
+// newProcessHandleForIdentity - a handle for the process `identity` was read from, if the PID has a
+// different owner by now the handle is gone right away, an empty identity binds to the current owner
+func newProcessHandleForIdentity(osProcess *os.Process, identity string) *processHandle {
+	startTicks, ok := identityStartTime(identity)
+	if !ok {
+		return newProcessHandle(osProcess)
+	}
+
+	thisRef := &processHandle{
+		pid:        osProcess.Pid,
+		startTicks: startTicks,
+		sync:       &sync.Mutex{},
+	}
+
+	// a zombie still is the process the identity was read from
+	fields, err := readProcStatFields(thisRef.pid)
+	if err != nil || procStatField(fields, 22) != fmt.Sprintf("%d", startTicks) {
+		thisRef.gone = true
+	}
+
+	return thisRef
+}
+
+// pin - opens the pidfd right away and keeps it, for children call it before they can be reaped
+func (thisRef *processHandle) pin() {
+	thisRef.sync.Lock()
+	thisRef.pinned = true
+	thisRef.sync.Unlock()
+
+	_, done := thisRef.acquire()
+	done()
+}
+
+// release - closes the pidfd of a pinned child once it was reaped, from then on the process is gone
+func (thisRef *processHandle) release() {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	thisRef.gone = true
+	thisRef.closeReleasedPidfd()
+}
+
+// closeReleasedPidfd - closes the pinned pidfd once released and no longer in use, call with `sync` held
+func (thisRef *processHandle) closeReleasedPidfd() {
+	if thisRef.pidfd != nil && thisRef.gone && thisRef.pidfdUsers == 0 {
+		thisRef.pidfd.Close()
+		thisRef.pidfd = nil
+	}
+}
+
+// acquire - the pidfd for one operation and the func to call when done with it, `nil` if pidfd is
+// not available or the process is gone
+func (thisRef *processHandle) acquire() (*os.File, func()) {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	if thisRef.gone || thisRef.pidfdUnavailable {
+		return nil, func() {}
+	}
+	if thisRef.pidfd != nil {
+		return thisRef.usePinnedPidfd()
+	}
+
+	fd, _, errno := unix.Syscall(unix.SYS_PIDFD_OPEN, uintptr(thisRef.pid), 0, 0)
+	if errno != 0 {
+		if errno == unix.ESRCH {
+			thisRef.gone = true
+		} else {
+			thisRef.pidfdUnavailable = true
+			logging.Debugf("%s: pidfd_open-FAIL for PID [%d], [%s], falling back to start-time checks", logID, thisRef.pid, errno.Error())
+		}
+
+		return nil, func() {}
+	}
+
+	pidfd := os.NewFile(fd, fmt.Sprintf("pidfd:%d", thisRef.pid))
+
+	// the PID might have been recycled between the moment it was read and `pidfd_open()`
+	if !thisRef.isSameProcess() {
+		pidfd.Close()
+		thisRef.gone = true
+		return nil, func() {}
+	}
+
+	if thisRef.pinned {
+		thisRef.pidfd = pidfd
+		return thisRef.usePinnedPidfd()
+	}
+
+	return pidfd, func() { pidfd.Close() }
+}
+
+// usePinnedPidfd - the pinned pidfd for one more operation, call with `sync` held
+func (thisRef *processHandle) usePinnedPidfd() (*os.File, func()) {
+	thisRef.pidfdUsers++
+
+	return thisRef.pidfd, func() {
+		thisRef.sync.Lock()
+		defer thisRef.sync.Unlock()
+
+		thisRef.pidfdUsers--
+		thisRef.closeReleasedPidfd()
+	}
+}
+
+// isAlive - `false` once the process has exited, even if its PID was reused since
+func (thisRef *processHandle) isAlive() bool {
+	pidfd, done := thisRef.acquire()
+	defer done()
+
+	if thisRef.isGone() {
+		return false
+	}
+
+	if pidfd != nil {
+		fds := []unix.PollFd{{Fd: int32(pidfd.Fd()), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, 0)
+		if err == nil {
+			return n == 0
+		}
+	}
+
+	return thisRef.isSameProcess()
+}
+
+// signal - sends `sig` to the process, never to a process that reused its PID
+func (thisRef *processHandle) signal(sig syscall.Signal) error {
+	pidfd, done := thisRef.acquire()
+	defer done()
+
+	if thisRef.isGone() {
+		return contracts.ErrProcessDoesNotExist
+	}
+
+	if pidfd != nil {
+		_, _, errno := unix.Syscall6(unix.SYS_PIDFD_SEND_SIGNAL, pidfd.Fd(), uintptr(sig), 0, 0, 0, 0)
+		if errno != 0 {
+			if errno == unix.ESRCH {
+				return contracts.ErrProcessDoesNotExist
+			}
+			return errno
+		}
+
+		return nil
+	}
+
+	if !thisRef.isSameProcess() {
+		return contracts.ErrProcessDoesNotExist
+	}
+
+	return unix.Kill(thisRef.pid, sig)
+}
+
+// wait - blocks until the process exits, works for children and non-children
+func (thisRef *processHandle) wait() {
+	pidfd, done := thisRef.acquire()
+	defer done()
+
+	if thisRef.isGone() {
+		return
+	}
+
+	if pidfd != nil {
+		fds := []unix.PollFd{{Fd: int32(pidfd.Fd()), Events: unix.POLLIN}}
+		for {
+			_, err := unix.Poll(fds, -1)
+			if err != unix.EINTR {
+				return
+			}
+		}
+	}
+
+	for thisRef.isSameProcess() {
+		time.Sleep(processHandlePollInterval)
+	}
+}
+
+func (thisRef *processHandle) isGone() bool {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	return thisRef.gone
+}
+
+// isSameProcess - the PID is still owned by a live process that started when this handle was created
+func (thisRef *processHandle) isSameProcess() bool {
+	fields, err := readProcStatFields(thisRef.pid)
+	if err != nil {
+		return false
+	}
+
+	switch procStatField(fields, 3) {
+	case "Z", "X", "x":
+		return false
+	}
+
+	if thisRef.startTicks == 0 {
+		return true
+	}
+
+	return procStatField(fields, 22) == fmt.Sprintf("%d", thisRef.startTicks)
+}
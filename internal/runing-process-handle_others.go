@@ -0,0 +1,99 @@
+// +build !linux
+
+package internal
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+const processHandlePollInterval = 500 * time.Millisecond
+
+// processHandle - without pidfd the best available reference is the OS process itself, for
+// processes that are not children the identity is checked before every signal
+type processHandle struct {
+	pid       int
+	osProcess *os.Process
+	identity  string
+}
+
+// findProcess - same as `os.FindProcess()`
+func findProcess(pid int) (*os.Process, error) {
+	return os.FindProcess(pid)
+}
+
+func newProcessHandle(osProcess *os.Process) *processHandle {
+	return &processHandle{
+		pid:       osProcess.Pid,
+		osProcess: osProcess,
+		identity:  "",
+	}
+}
+
+// newProcessHandleForIdentity - a handle for the process `identity` was read from, an empty identity
+// binds to the current owner of the PID
+func newProcessHandleForIdentity(osProcess *os.Process, identity string) *processHandle {
+	thisRef := newProcessHandle(osProcess)
+	thisRef.identity = identity
+
+	return thisRef
+}
+
+// isSameProcess - the PID is still owned by the process the identity was read from, there is
+// a window between this check and the signal that only pidfd closes
+func (thisRef *processHandle) isSameProcess() bool {
+	if len(thisRef.identity) == 0 {
+		return true
+	}
+
+	rp, err := getRuntimeProcessByPID(thisRef.pid)
+	if err != nil || rp.State == contracts.ProcessStateNonExistent {
+		return false
+	}
+
+	return rp.Identity == thisRef.identity
+}
+
+// isGone - the PID belongs to another process than the identity was read from
+func (thisRef *processHandle) isGone() bool {
+	return !thisRef.isSameProcess()
+}
+
+// pin - nothing to pin on this platform
+func (thisRef *processHandle) pin() {}
+
+// release - nothing to release on this platform
+func (thisRef *processHandle) release() {}
+
+// isAlive - liveness is decided by `Details()` on this platform, unless the handle is bound to an identity
+func (thisRef *processHandle) isAlive() bool {
+	return thisRef.isSameProcess()
+}
+
+// signal - sends `sig` to the process, not to a process that reused its PID
+func (thisRef *processHandle) signal(sig syscall.Signal) error {
+	if !thisRef.isSameProcess() {
+		return contracts.ErrProcessDoesNotExist
+	}
+
+	return thisRef.osProcess.Signal(sig)
+}
+
+// wait - blocks until the process exits, works for children and non-children
+func (thisRef *processHandle) wait() {
+	for {
+		rp, err := getRuntimeProcessByPID(thisRef.pid)
+		if err != nil ||
+			rp.State == contracts.ProcessStateNonExistent ||
+			rp.State == contracts.ProcessStateObsolete ||
+			rp.State == contracts.ProcessStateDead ||
+			(len(thisRef.identity) > 0 && rp.Identity != thisRef.identity) {
+			return
+		}
+
+		time.Sleep(processHandlePollInterval)
+	}
+}
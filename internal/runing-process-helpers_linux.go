@@ -0,0 +1,357 @@
+// +build linux
+
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+// platformProcAttrs - Linux only attributes
+func platformProcAttrs(attrs *unix.SysProcAttr, processTemplate contracts.ProcessTemplate) error {
+	for _, name := range processTemplate.AmbientCapabilities {
+		capability, err := capabilityFromName(name)
+		if err != nil {
+			return err
+		}
+
+		attrs.AmbientCaps = append(attrs.AmbientCaps, capability)
+	}
+
+	sandbox := processTemplate.Sandbox
+	if sandbox == nil {
+		return nil
+	}
+
+	attrs.Cloneflags = sandbox.Cloneflags
+	attrs.Chroot = sandbox.Chroot
+
+	for _, mapping := range sandbox.UIDMappings {
+		attrs.UidMappings = append(attrs.UidMappings, syscall.SysProcIDMap{ContainerID: mapping.ContainerID, HostID: mapping.HostID, Size: mapping.Size})
+	}
+	for _, mapping := range sandbox.GIDMappings {
+		attrs.GidMappings = append(attrs.GidMappings, syscall.SysProcIDMap{ContainerID: mapping.ContainerID, HostID: mapping.HostID, Size: mapping.Size})
+	}
+
+	return nil
+}
+
+// startCommand - starts `osCmd`, no_new_privs, mounts and scheduling can't be set up through `SysProcAttr`,
+// but the child inherits them from the thread that forks it, so that is done from a throw away thread.
+// The returned func removes the mount targets created inside `Chroot`, to be called once the process exits
+func startCommand(osCmd *exec.Cmd, processTemplate contracts.ProcessTemplate) (func(), error) {
+	sandbox := processTemplate.Sandbox
+	needsMounts := sandbox != nil && (len(sandbox.ReadOnlyBindMounts) > 0 || sandbox.PrivateTmp)
+
+	if !processTemplate.NoNewPrivileges && !needsMounts && processTemplate.Scheduling == nil {
+		return func() {}, osCmd.Start()
+	}
+
+	var created []mountTarget
+	result := make(chan error, 1)
+	go func() {
+		// never unlocked, so the thread exits with the goroutine and nothing leaks to other goroutines
+		runtime.LockOSThread()
+
+		if needsMounts {
+			var err error
+			if created, err = setupSandboxMounts(sandbox); err != nil {
+				result <- err
+				return
+			}
+		}
+
+		if processTemplate.NoNewPrivileges {
+			if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+				result <- err
+				return
+			}
+		}
+
+		if processTemplate.Scheduling != nil {
+			if err := applyThreadScheduling(processTemplate.Scheduling); err != nil {
+				result <- err
+				return
+			}
+		}
+
+		result <- osCmd.Start()
+	}()
+
+	cleanup := func() {
+		removeMountTargets(sandboxRoot(sandbox), created)
+	}
+
+	if err := <-result; err != nil {
+		cleanup()
+		return func() {}, err
+	}
+
+	return cleanup, nil
+}
+
+// mountTarget - a file or folder created inside the root so something could be mounted on it
+type mountTarget struct {
+	name  string
+	isDir bool
+}
+
+// sandboxRoot - the folder the mount targets are resolved in
+func sandboxRoot(sandbox *contracts.ProcessSandbox) string {
+	if sandbox == nil || len(sandbox.Chroot) == 0 {
+		return "/"
+	}
+
+	return sandbox.Chroot
+}
+
+// setupSandboxMounts - moves the current thread into a private mount namespace and mounts what `sandbox` asks for,
+// the rest of the process and the host don't see any of it. Targets are resolved inside `Chroot`, so symlinks in
+// it can't point a mount, or a folder created for one, outside of it
+func setupSandboxMounts(sandbox *contracts.ProcessSandbox) ([]mountTarget, error) {
+	created := []mountTarget{}
+
+	if err := unix.Unshare(unix.CLONE_NEWNS); err != nil {
+		return created, err
+	}
+
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return created, err
+	}
+
+	// opened after the unshare, so the descriptors point into the private mounts
+	rootFD, err := unix.Open(sandboxRoot(sandbox), unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return created, err
+	}
+	defer unix.Close(rootFD)
+
+	for _, source := range sandbox.ReadOnlyBindMounts {
+		fi, err := os.Stat(source)
+		if err != nil {
+			return created, err
+		}
+
+		targetFD, err := ensureMountTarget(rootFD, source, fi.IsDir(), 0755, &created)
+		if err != nil {
+			return created, err
+		}
+
+		err = unix.Mount(source, procSelfFD(targetFD), "", unix.MS_BIND|unix.MS_REC, "")
+		unix.Close(targetFD)
+		if err != nil {
+			return created, err
+		}
+
+		if err := remountReadOnly(rootFD, source); err != nil {
+			return created, err
+		}
+	}
+
+	if sandbox.PrivateTmp {
+		targetFD, err := ensureMountTarget(rootFD, "/tmp", true, 01777, &created)
+		if err != nil {
+			return created, err
+		}
+
+		err = unix.Mount("tmpfs", procSelfFD(targetFD), "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777")
+		unix.Close(targetFD)
+		if err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+func procSelfFD(fd int) string {
+	return fmt.Sprintf("/proc/self/fd/%d", fd)
+}
+
+// openInRoot - opens `name` below `rootFD` as an `O_PATH` descriptor, symlinks resolve as if `rootFD` was `/`,
+// so nothing outside of it is reached. Kernels before 5.6 have no `openat2()`, symlinks are refused there
+func openInRoot(rootFD int, name string) (int, error) {
+	name = strings.TrimPrefix(filepath.Clean("/"+name), "/")
+	if len(name) == 0 {
+		return unix.Openat(rootFD, ".", unix.O_PATH|unix.O_CLOEXEC, 0)
+	}
+
+	fd, err := unix.Openat2(rootFD, name, &unix.OpenHow{
+		Flags:   unix.O_PATH | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS,
+	})
+	if err != unix.ENOSYS {
+		return fd, err
+	}
+
+	fd, err = unix.Openat(rootFD, ".", unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		next, err := unix.Openat(fd, component, unix.O_PATH|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+		unix.Close(fd)
+		if err != nil {
+			return -1, err
+		}
+
+		stat := unix.Stat_t{}
+		if err := unix.Fstat(next, &stat); err != nil || stat.Mode&unix.S_IFMT == unix.S_IFLNK {
+			unix.Close(next)
+			return -1, unix.ELOOP
+		}
+
+		fd = next
+	}
+
+	return fd, nil
+}
+
+// ensureMountTarget - a bind mount needs a target of the same kind as the source, the missing parts of
+// `name` are created inside the root and added to `created`, returns an `O_PATH` descriptor of the target
+func ensureMountTarget(rootFD int, name string, isDir bool, mode uint32, created *[]mountTarget) (int, error) {
+	fd, err := openInRoot(rootFD, name)
+	if err != unix.ENOENT {
+		return fd, err
+	}
+
+	parentFD, err := ensureMountTarget(rootFD, filepath.Dir(name), true, 0755, created)
+	if err != nil {
+		return -1, err
+	}
+	defer unix.Close(parentFD)
+
+	// `*at()` only looks at the last component, which is created, never followed
+	base := filepath.Base(name)
+	if isDir {
+		err = unix.Mkdirat(parentFD, base, mode)
+	} else {
+		fd, err = unix.Openat(parentFD, base, unix.O_CREAT|unix.O_EXCL|unix.O_WRONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, mode)
+		if err == nil {
+			unix.Close(fd)
+		}
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	*created = append(*created, mountTarget{name: name, isDir: isDir})
+
+	return unix.Openat(parentFD, base, unix.O_PATH|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+}
+
+// removeMountTargets - removes what `ensureMountTarget()` created, deepest first, folders the process
+// left something in stay
+func removeMountTargets(root string, created []mountTarget) {
+	if len(created) == 0 {
+		return
+	}
+
+	rootFD, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return
+	}
+	defer unix.Close(rootFD)
+
+	for i := len(created) - 1; i >= 0; i-- {
+		parentFD, err := openInRoot(rootFD, filepath.Dir(created[i].name))
+		if err != nil {
+			continue
+		}
+
+		flags := 0
+		if created[i].isDir {
+			flags = unix.AT_REMOVEDIR
+		}
+
+		unix.Unlinkat(parentFD, filepath.Base(created[i].name), flags)
+		unix.Close(parentFD)
+	}
+}
+
+// remountReadOnly - a read-only bind remount only covers one mount, the submounts `MS_REC` brought along
+// stay writable, so every mount at or below the target is remounted, keeping its other flags
+func remountReadOnly(rootFD int, name string) error {
+	targetFD, err := openInRoot(rootFD, name)
+	if err != nil {
+		return err
+	}
+
+	target, err := os.Readlink(procSelfFD(targetFD))
+	unix.Close(targetFD)
+	if err != nil {
+		return err
+	}
+
+	// the mounts of this thread, not the ones of the process
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/self/task/%d/mountinfo", unix.Gettid()))
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+
+		mountPoint := unescapeMountInfo(fields[4])
+		if mountPoint != target && !strings.HasPrefix(mountPoint, strings.TrimSuffix(target, "/")+"/") {
+			continue
+		}
+
+		flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY)
+		for _, option := range strings.Split(fields[5], ",") {
+			flags |= mountOptionFlags[option]
+		}
+
+		if err := unix.Mount("", mountPoint, "", flags, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mountOptionFlags - the per mount options of `mountinfo` a remount has to repeat, some can't be dropped in a user namespace
+var mountOptionFlags = map[string]uintptr{
+	"nosuid":     unix.MS_NOSUID,
+	"nodev":      unix.MS_NODEV,
+	"noexec":     unix.MS_NOEXEC,
+	"noatime":    unix.MS_NOATIME,
+	"nodiratime": unix.MS_NODIRATIME,
+	"relatime":   unix.MS_RELATIME,
+}
+
+// unescapeMountInfo - `mountinfo` writes space, tab, newline and backslash as octal escapes like `\040`
+func unescapeMountInfo(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+4 <= len(value) {
+			if code, err := strconv.ParseUint(value[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+
+		sb.WriteByte(value[i])
+	}
+
+	return sb.String()
+}
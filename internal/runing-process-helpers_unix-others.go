@@ -0,0 +1,29 @@
+// +build !windows,!linux
+
+package internal
+
+import (
+	"os/exec"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+// platformProcAttrs - capabilities, no_new_privs, sandboxing and scheduling other than nice are Linux only
+func platformProcAttrs(attrs *unix.SysProcAttr, processTemplate contracts.ProcessTemplate) error {
+	if len(processTemplate.AmbientCapabilities) > 0 || processTemplate.NoNewPrivileges || processTemplate.Sandbox != nil {
+		return contracts.ErrNotAvailable
+	}
+
+	if scheduling := processTemplate.Scheduling; scheduling != nil {
+		if len(scheduling.CPUAffinity) > 0 || len(scheduling.Policy) > 0 || len(scheduling.IOClass) > 0 || scheduling.AppliesOOMScoreAdj() {
+			return contracts.ErrNotAvailable
+		}
+	}
+
+	return nil
+}
+
+func startCommand(osCmd *exec.Cmd, processTemplate contracts.ProcessTemplate) (func(), error) {
+	return func() {}, osCmd.Start()
+}
@@ -3,7 +3,70 @@
 package internal
 
 import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"github.com/remoteit/systemkit-processes/contracts"
 	"golang.org/x/sys/unix"
 )
 
-var procAttrs = &unix.SysProcAttr{}
+// procAttrs - the OS attributes for starting `processTemplate`
+func procAttrs(processTemplate contracts.ProcessTemplate) (*unix.SysProcAttr, error) {
+	attrs := &unix.SysProcAttr{}
+
+	if len(processTemplate.User) > 0 {
+		credential, err := credentialForUser(processTemplate.User)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs.Credential = credential
+	}
+
+	if err := platformProcAttrs(attrs, processTemplate); err != nil {
+		return nil, err
+	}
+
+	return attrs, nil
+}
+
+// credentialForUser - `name` is a user name or a numeric ID, the groups come from the user database
+func credentialForUser(name string) (*syscall.Credential, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if _, convErr := strconv.Atoi(name); convErr != nil {
+			return nil, err
+		}
+
+		if u, err = user.LookupId(name); err != nil {
+			return nil, err
+		}
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("bad user ID [%s] for [%s]", u.Uid, name)
+	}
+
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("bad group ID [%s] for [%s]", u.Gid, name)
+	}
+
+	credential := &syscall.Credential{
+		Uid:    uint32(uid),
+		Gid:    uint32(gid),
+		Groups: []uint32{},
+	}
+
+	groupIDs, _ := u.GroupIds()
+	for _, groupID := range groupIDs {
+		if gid, err := strconv.ParseUint(groupID, 10, 32); err == nil {
+			credential.Groups = append(credential.Groups, uint32(gid))
+		}
+	}
+
+	return credential, nil
+}
@@ -3,7 +3,25 @@
 package internal
 
 import (
+	"os/exec"
+
+	"github.com/remoteit/systemkit-processes/contracts"
 	"golang.org/x/sys/windows"
 )
 
-var procAttrs = &windows.SysProcAttr{}
+// procAttrs - the OS attributes for starting `processTemplate`, users, capabilities, no_new_privs, sandboxing
+// and scheduling are Unix only, the process gets its own process group so console control events reach only it
+func procAttrs(processTemplate contracts.ProcessTemplate) (*windows.SysProcAttr, error) {
+	if len(processTemplate.User) > 0 || len(processTemplate.AmbientCapabilities) > 0 || processTemplate.NoNewPrivileges ||
+		processTemplate.Sandbox != nil || processTemplate.Scheduling != nil {
+		return nil, contracts.ErrNotAvailable
+	}
+
+	return &windows.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP,
+	}, nil
+}
+
+func startCommand(osCmd *exec.Cmd, processTemplate contracts.ProcessTemplate) (func(), error) {
+	return func() {}, osCmd.Start()
+}
@@ -0,0 +1,61 @@
+// +build windows
+
+package internal
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// processMemoryCounters - `PROCESS_MEMORY_COUNTERS` from psapi.h
+type processMemoryCounters struct {
+	CB                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// getProcessMemoryInfo - the kernel32 export of `GetProcessMemoryInfo`, Windows 7 and later
+var getProcessMemoryInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("K32GetProcessMemoryInfo")
+
+// readWindowsMetrics - CPU times in 100ns units, the working set as the resident size and private bytes as
+// the virtual size, processes of other users can't be opened without admin rights
+func readWindowsMetrics(pid int, rp *contracts.RuntimeProcess) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(handle)
+
+	var creationTime, exitTime, kernelTime, userTime windows.Filetime
+	if windows.GetProcessTimes(handle, &creationTime, &exitTime, &kernelTime, &userTime) == nil {
+		rp.CPU.UserTicks = uint64(userTime.HighDateTime)<<32 | uint64(userTime.LowDateTime)
+		rp.CPU.SystemTicks = uint64(kernelTime.HighDateTime)<<32 | uint64(kernelTime.LowDateTime)
+		rp.CPU.TicksPerSecond = 10000000
+	}
+
+	if getProcessMemoryInfo.Find() != nil {
+		return
+	}
+
+	counters := processMemoryCounters{}
+	counters.CB = uint32(unsafe.Sizeof(counters))
+	ret, _, _ := getProcessMemoryInfo.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.CB))
+	if ret == 0 {
+		return
+	}
+
+	rp.Memory.ResidentSize = uint64(counters.WorkingSetSize)
+	rp.Memory.PeakResidentSize = uint64(counters.PeakWorkingSetSize)
+	rp.Memory.VirtualSize = uint64(counters.PagefileUsage)
+}
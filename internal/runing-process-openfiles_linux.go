@@ -0,0 +1,391 @@
+// +build linux
+
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// GetOpenFiles - lists the file descriptors of a process, sockets are resolved against the
+// network tables of the network namespace the process lives in
+func GetOpenFiles(pid int) ([]contracts.OpenFile, error) {
+	folder := fmt.Sprintf("/proc/%d", pid)
+
+	fis, err := ioutil.ReadDir(path.Join(folder, "fd"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, contracts.ErrProcessDoesNotExist
+		}
+
+		return nil, err
+	}
+
+	var sockets map[uint64]contracts.Socket
+
+	results := make([]contracts.OpenFile, 0, len(fis))
+	for _, fi := range fis {
+		fd, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+
+		// the fd might have been closed since the listing, skip it
+		fdPath := path.Join(folder, "fd", fi.Name())
+		target, err := os.Readlink(fdPath)
+		if err != nil {
+			continue
+		}
+
+		openFile := contracts.OpenFile{
+			FD:     fd,
+			Target: target,
+			Type:   contracts.FileDescriptorTypeUnknown,
+		}
+
+		switch {
+		case strings.HasPrefix(target, "socket:["):
+			openFile.Type = contracts.FileDescriptorTypeSocket
+			openFile.Inode = parseBracketedInode(target)
+		case strings.HasPrefix(target, "pipe:["):
+			openFile.Type = contracts.FileDescriptorTypePipe
+			openFile.Inode = parseBracketedInode(target)
+		case strings.HasPrefix(target, "anon_inode:"):
+			openFile.Type = contracts.FileDescriptorTypeAnonInode
+		default:
+			if stat, err := os.Stat(fdPath); err == nil {
+				openFile.Inode = fileInode(stat)
+
+				switch {
+				case stat.Mode()&os.ModeDevice != 0:
+					openFile.Type = contracts.FileDescriptorTypeDevice
+				case stat.Mode()&os.ModeNamedPipe != 0:
+					openFile.Type = contracts.FileDescriptorTypePipe
+				default:
+					openFile.Type = contracts.FileDescriptorTypeFile
+				}
+			}
+		}
+
+		// flags and position
+		data, _ := ioutil.ReadFile(path.Join(folder, "fdinfo", fi.Name()))
+		for _, line := range strings.Split(string(data), "\n") {
+			props := strings.SplitN(line, ":", 2)
+			if len(props) < 2 {
+				continue
+			}
+
+			val := strings.TrimSpace(props[1])
+			switch props[0] {
+			case "pos":
+				openFile.Position, _ = strconv.ParseInt(val, 10, 64)
+			case "flags":
+				flags, _ := strconv.ParseInt(val, 8, 64)
+				openFile.Flags = int(flags)
+			}
+		}
+
+		if openFile.Type == contracts.FileDescriptorTypeSocket {
+			if sockets == nil {
+				sockets = readSocketTables(pid)
+			}
+
+			if socket, ok := sockets[openFile.Inode]; ok {
+				openFile.Socket = &socket
+			}
+		}
+
+		results = append(results, openFile)
+	}
+
+	return results, nil
+}
+
+// readSocketTables - all sockets from `/proc/<pid>/net/*` by inode
+func readSocketTables(pid int) map[uint64]contracts.Socket {
+	sockets := map[uint64]contracts.Socket{}
+
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		readInetSocketTable(pid, protocol, sockets)
+	}
+	readUnixSocketTable(pid, sockets)
+
+	return sockets
+}
+
+// readInetSocketTable - parses lines like
+// `sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode`
+func readInetSocketTable(pid int, protocol string, sockets map[uint64]contracts.Socket) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/%s", pid, protocol))
+	if err != nil {
+		return
+	}
+
+	for index, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if index == 0 || len(fields) < 10 {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+
+		localAddress, localPort := parseInetAddress(fields[1])
+		remoteAddress, remotePort := parseInetAddress(fields[2])
+
+		sockets[inode] = contracts.Socket{
+			Protocol:      protocol,
+			LocalAddress:  localAddress,
+			LocalPort:     localPort,
+			RemoteAddress: remoteAddress,
+			RemotePort:    remotePort,
+			State:         inetSocketState(protocol, fields[3]),
+			Inode:         inode,
+		}
+	}
+}
+
+// readUnixSocketTable - parses lines like `Num RefCount Protocol Flags Type St Inode Path`
+func readUnixSocketTable(pid int, sockets map[uint64]contracts.Socket) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/unix", pid))
+	if err != nil {
+		return
+	}
+
+	const acceptConnections = 0x10000 // __SO_ACCEPTCON
+
+	for index, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if index == 0 || len(fields) < 7 {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		flags, _ := strconv.ParseUint(fields[3], 16, 64)
+		state := "UNCONNECTED"
+		if flags&acceptConnections != 0 {
+			state = "LISTEN"
+		} else if fields[5] == "03" {
+			state = "CONNECTED"
+		}
+
+		socket := contracts.Socket{
+			Protocol: "unix",
+			State:    state,
+			Inode:    inode,
+		}
+		if len(fields) > 7 {
+			socket.LocalAddress = fields[7]
+		}
+
+		sockets[inode] = socket
+	}
+}
+
+// parseInetAddress - decodes `0100007F:0035` into `127.0.0.1` and 53, the address is
+// made of 32 bit words in host byte order
+func parseInetAddress(hexAddress string) (string, int) {
+	parts := strings.Split(hexAddress, ":")
+	if len(parts) != 2 {
+		return "", 0
+	}
+
+	port, _ := strconv.ParseUint(parts[1], 16, 16)
+
+	// the kernel prints each word of the network order address as a host order number
+	if len(parts[0]) == 0 || len(parts[0])%8 != 0 {
+		return "", int(port)
+	}
+
+	ip := make(net.IP, len(parts[0])/2)
+	for i := 0; i < len(ip); i += 4 {
+		word, err := strconv.ParseUint(parts[0][i*2:i*2+8], 16, 32)
+		if err != nil {
+			return "", int(port)
+		}
+
+		nativeEndian.PutUint32(ip[i:], uint32(word))
+	}
+
+	return ip.String(), int(port)
+}
+
+// inetSocketState - copied from include/net/tcp_states.h, UDP reuses the same values
+func inetSocketState(protocol string, hexState string) string {
+	state, _ := strconv.ParseUint(hexState, 16, 8)
+
+	if protocol == "udp" || protocol == "udp6" {
+		if state == 1 {
+			return "ESTABLISHED"
+		}
+		return "UNCONNECTED"
+	}
+
+	switch state {
+	case 0x01:
+		return "ESTABLISHED"
+	case 0x02:
+		return "SYN_SENT"
+	case 0x03:
+		return "SYN_RECV"
+	case 0x04:
+		return "FIN_WAIT1"
+	case 0x05:
+		return "FIN_WAIT2"
+	case 0x06:
+		return "TIME_WAIT"
+	case 0x07:
+		return "CLOSE"
+	case 0x08:
+		return "CLOSE_WAIT"
+	case 0x09:
+		return "LAST_ACK"
+	case 0x0A:
+		return "LISTEN"
+	case 0x0B:
+		return "CLOSING"
+	}
+
+	return "UNKNOWN"
+}
+
+// parseBracketedInode - `socket:[1234]` -> 1234
+func parseBracketedInode(target string) uint64 {
+	start := strings.IndexByte(target, '[')
+	end := strings.IndexByte(target, ']')
+	if start < 0 || end < start {
+		return 0
+	}
+
+	inode, _ := strconv.ParseUint(target[start+1:end], 10, 64)
+
+	return inode
+}
+
+func fileInode(fi os.FileInfo) uint64 {
+	if stat, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return stat.Ino
+	}
+
+	return 0
+}
+
+// GetListeningPorts - the TCP and UDP sockets a process is listening on
+func GetListeningPorts(pid int) ([]contracts.Socket, error) {
+	openFiles, err := GetOpenFiles(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []contracts.Socket{}
+	for _, openFile := range openFiles {
+		if openFile.Socket != nil && openFile.Socket.Protocol != "unix" && openFile.Socket.IsListening() {
+			results = append(results, *openFile.Socket)
+		}
+	}
+
+	return results, nil
+}
+
+// GetProcessIDsByPort - PIDs of the processes listening on `port`, `protocol` is one of
+// tcp, tcp6, udp, udp6; tcp and udp match both IPv4 and IPv6 sockets. The socket tables are
+// read once per network namespace, namespaces of processes the caller can't inspect are missed
+func GetProcessIDsByPort(protocol string, port int) ([]int, error) {
+	protocols := []string{protocol}
+	switch protocol {
+	case "tcp":
+		protocols = []string{"tcp", "tcp6"}
+	case "udp":
+		protocols = []string{"udp", "udp6"}
+	case "tcp6", "udp6":
+	default:
+		return nil, fmt.Errorf("%s: unsupported protocol [%s]", logID, protocol)
+	}
+
+	fis, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	pids := []int{}
+	for _, fi := range fis {
+		pid, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+
+		pids = append(pids, pid)
+	}
+
+	// 1 - find the inodes of the matching sockets, `/proc/<pid>/net` shows the namespace of `<pid>`
+	sockets := map[uint64]contracts.Socket{}
+	for _, p := range protocols {
+		readInetSocketTable(os.Getpid(), p, sockets)
+	}
+
+	namespaces := map[string]bool{}
+	if ns, err := os.Readlink("/proc/self/ns/net"); err == nil {
+		namespaces[ns] = true
+	}
+
+	for _, pid := range pids {
+		ns, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+		if err != nil || namespaces[ns] {
+			continue
+		}
+		namespaces[ns] = true
+
+		for _, p := range protocols {
+			readInetSocketTable(pid, p, sockets)
+		}
+	}
+
+	inodes := map[uint64]bool{}
+	for inode, socket := range sockets {
+		if socket.LocalPort == port && socket.IsListening() {
+			inodes[inode] = true
+		}
+	}
+
+	if len(inodes) == 0 {
+		return []int{}, nil
+	}
+
+	// 2 - find who holds them
+	results := []int{}
+	for _, pid := range pids {
+		fdFolder := fmt.Sprintf("/proc/%d/fd", pid)
+		fds, err := ioutil.ReadDir(fdFolder)
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := os.Readlink(path.Join(fdFolder, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+
+			if inodes[parseBracketedInode(target)] {
+				results = append(results, pid)
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
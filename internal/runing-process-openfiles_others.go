@@ -0,0 +1,20 @@
+// +build !linux
+
+package internal
+
+import "github.com/remoteit/systemkit-processes/contracts"
+
+// GetOpenFiles - not available on this platform
+func GetOpenFiles(pid int) ([]contracts.OpenFile, error) {
+	return nil, contracts.ErrNotAvailable
+}
+
+// GetListeningPorts - not available on this platform
+func GetListeningPorts(pid int) ([]contracts.Socket, error) {
+	return nil, contracts.ErrNotAvailable
+}
+
+// GetProcessIDsByPort - not available on this platform
+func GetProcessIDsByPort(protocol string, port int) ([]int, error) {
+	return nil, contracts.ErrNotAvailable
+}
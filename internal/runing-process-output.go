@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// outputTail - the last lines a process wrote to STDOUT and STDERR, across restarts
+type outputTail struct {
+	sync  *sync.Mutex
+	size  int
+	lines []contracts.ProcessOutputLine
+}
+
+func newOutputTail(size int) *outputTail {
+	return &outputTail{
+		sync:  &sync.Mutex{},
+		size:  size,
+		lines: []contracts.ProcessOutputLine{},
+	}
+}
+
+func (thisRef *outputTail) add(stream string, line []byte) {
+	if thisRef.size <= 0 {
+		return
+	}
+
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	thisRef.lines = append(thisRef.lines, contracts.ProcessOutputLine{
+		Time:   time.Now(),
+		Stream: stream,
+		Text:   string(line),
+	})
+
+	if len(thisRef.lines) > thisRef.size {
+		thisRef.lines = thisRef.lines[len(thisRef.lines)-thisRef.size:]
+	}
+}
+
+// last - up to `count` of the latest lines, oldest first, all of them for `count` <= 0
+func (thisRef *outputTail) last(count int) []contracts.ProcessOutputLine {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	start := 0
+	if count > 0 && count < len(thisRef.lines) {
+		start = len(thisRef.lines) - count
+	}
+
+	lines := make([]contracts.ProcessOutputLine, len(thisRef.lines)-start)
+	copy(lines, thisRef.lines[start:])
+
+	return lines
+}
+
+// outputHandler - what `readOutput()` calls for each line of `stream` during `run`
+func (thisRef *runingProcess) outputHandler(run *processRun, stream string) func(line []byte) {
+	return func(line []byte) {
+		run.outputSeen()
+		thisRef.tail.add(stream, line)
+	}
+}
+
+// Output - the last `lines` lines the process wrote, see `ProcessTemplate.OutputTailLines`
+func (thisRef *runingProcess) Output(lines int) []contracts.ProcessOutputLine {
+	return thisRef.tail.last(lines)
+}
@@ -0,0 +1,131 @@
+// +build linux
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var errMalformedProcStat = errors.New("malformed /proc/<pid>/stat")
+
+// readProcStatFields - reads `/proc/<pid>/stat` and returns the fields that follow `comm`,
+// index 0 is field 3 (state) as documented in `man 5 proc`
+func readProcStatFields(pid int) ([]string, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	// `comm` is wrapped in parens and may contain spaces or parens itself
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 || end+2 > len(data) {
+		return nil, errMalformedProcStat
+	}
+
+	return strings.Fields(string(data[end+2:])), nil
+}
+
+// procStatField - returns field number `field` (1-based, as in `man 5 proc`) from the output of `readProcStatFields()`
+func procStatField(fields []string, field int) string {
+	index := field - 3
+	if index < 0 || index >= len(fields) {
+		return ""
+	}
+
+	return fields[index]
+}
+
+// readProcStartTicks - returns the start time of a process in clock ticks since boot
+func readProcStartTicks(pid int) (uint64, error) {
+	fields, err := readProcStatFields(pid)
+	if err != nil {
+		return 0, err
+	}
+
+	startTicks, err := strconv.ParseUint(procStatField(fields, 22), 10, 64)
+	if err != nil {
+		return 0, errMalformedProcStat
+	}
+
+	return startTicks, nil
+}
+
+// clockTicksPerSecond - USER_HZ, the unit of the time fields in `/proc`, fixed at 100 for all Linux ABIs
+const clockTicksPerSecond = 100
+
+var (
+	bootInfoSync = &sync.Once{}
+	bootTimeVal  = time.Unix(0, 0)
+	bootIDVal    = ""
+)
+
+func readBootInfo() {
+	bootInfoSync.Do(func() {
+		data, _ := ioutil.ReadFile("/proc/sys/kernel/random/boot_id")
+		bootIDVal = strings.TrimSpace(string(data))
+
+		data, _ = ioutil.ReadFile("/proc/stat")
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "btime ") {
+				btime, _ := strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+				bootTimeVal = time.Unix(btime, 0)
+				break
+			}
+		}
+	})
+}
+
+// bootTime - the wall-clock time the system booted at, `btime` from `/proc/stat`
+func bootTime() time.Time {
+	readBootInfo()
+	return bootTimeVal
+}
+
+// bootID - a random ID the kernel generates on each boot
+func bootID() string {
+	readBootInfo()
+	return bootIDVal
+}
+
+// ticksToDuration - converts clock ticks to a duration
+func ticksToDuration(ticks uint64) time.Duration {
+	return time.Duration(ticks) * time.Second / clockTicksPerSecond
+}
+
+// parseProcStatusKB - parses a `/proc/<pid>/status` size like "1234 kB" into bytes
+func parseProcStatusKB(val string) uint64 {
+	fields := strings.Fields(val)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	size, _ := strconv.ParseUint(fields[0], 10, 64)
+
+	return size * 1024
+}
+
+// ttyNameFromProcTTYNr - decodes `tty_nr` from `/proc/<pid>/stat`, pseudo terminals are mapped directly,
+// everything else is looked up in `/dev`
+func ttyNameFromProcTTYNr(ttyNr uint32) string {
+	if ttyNr == 0 {
+		return ""
+	}
+
+	major := (ttyNr >> 8) & 0xfff
+	minor := (ttyNr & 0xff) | ((ttyNr >> 12) & 0xfff00)
+
+	// UNIX98_PTY_SLAVE_MAJOR, up to UNIX98_PTY_MAJOR_COUNT majors
+	if major >= 136 && major <= 143 {
+		return fmt.Sprintf("/dev/pts/%d", (major-136)*256+minor)
+	}
+
+	return ttyNameFromDevice(unix.Mkdev(major, minor))
+}
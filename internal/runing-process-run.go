@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// processRun - how one start of a child went, every start gets its own so a previous run
+// that is reaped late can't overwrite the result of the next one
+type processRun struct {
+	sync         *sync.Mutex
+	exited       chan struct{}
+	processID    int
+	startedAt    time.Time
+	exitReason   string
+	exitState    *os.ProcessState
+	exitedAt     time.Time
+	lastOutputAt time.Time
+}
+
+func newProcessRun() *processRun {
+	closedChannel := make(chan struct{})
+	close(closedChannel)
+
+	return &processRun{
+		sync:         &sync.Mutex{},
+		exited:       closedChannel,
+		processID:    0,
+		startedAt:    time.Unix(0, 0),
+		exitReason:   "",
+		exitState:    nil,
+		exitedAt:     time.Unix(0, 0),
+		lastOutputAt: time.Now(),
+	}
+}
+
+// started - the child of this run is up
+func (thisRef *processRun) started(processID int, startedAt time.Time, exited chan struct{}) {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	thisRef.processID = processID
+	thisRef.startedAt = startedAt
+	thisRef.exited = exited
+}
+
+// setExitReason - the first reason sticks
+func (thisRef *processRun) setExitReason(reason string) {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	if len(thisRef.exitReason) == 0 {
+		thisRef.exitReason = reason
+	}
+}
+
+// markExited - records how the child ended, one nobody stopped exited on its own
+func (thisRef *processRun) markExited(state *os.ProcessState) {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	thisRef.exitState = state
+	thisRef.exitedAt = time.Now()
+
+	if len(thisRef.exitReason) == 0 {
+		thisRef.exitReason = contracts.ExitReasonExited
+	}
+}
+
+func (thisRef *processRun) outputSeen() {
+	thisRef.sync.Lock()
+	thisRef.lastOutputAt = time.Now()
+	thisRef.sync.Unlock()
+}
+
+func (thisRef *processRun) lastOutput() time.Time {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	return thisRef.lastOutputAt
+}
+
+// result - the exit code is -1 for children killed by a signal
+func (thisRef *processRun) result() contracts.ProcessExitResult {
+	thisRef.sync.Lock()
+	defer thisRef.sync.Unlock()
+
+	exitCode := 0
+	if thisRef.exitState != nil {
+		exitCode = thisRef.exitState.ExitCode()
+	}
+
+	return contracts.ProcessExitResult{
+		ProcessID: thisRef.processID,
+		ExitCode:  exitCode,
+		Reason:    thisRef.exitReason,
+		StartedAt: thisRef.startedAt,
+		ExitedAt:  thisRef.exitedAt,
+	}
+}
+
+func (thisRef *runingProcess) currentRun() *processRun {
+	thisRef.runSync.Lock()
+	defer thisRef.runSync.Unlock()
+
+	return thisRef.run
+}
+
+// RuningProcessCurrentRun - the exit channel of the latest start of `rp` and how that start ended,
+// unlike `ExitResult()` the result keeps describing that start after `rp` is started again
+func RuningProcessCurrentRun(rp contracts.RuningProcess) (<-chan struct{}, func() contracts.ProcessExitResult) {
+	p, ok := rp.(*runingProcess)
+	if !ok {
+		return rp.Exited(), rp.ExitResult
+	}
+
+	run := p.currentRun()
+
+	run.sync.Lock()
+	defer run.sync.Unlock()
+
+	return run.exited, run.result
+}
+
+// SetRuningProcessExitReason - records why `rp` is about to exit, before stopping it, the first reason sticks
+func SetRuningProcessExitReason(rp contracts.RuningProcess, reason string) {
+	if p, ok := rp.(*runingProcess); ok {
+		p.currentRun().setExitReason(reason)
+	}
+}
+
+// RuningProcessLastOutputAt - when `rp` last wrote a line to STDOUT or STDERR, or when it started
+func RuningProcessLastOutputAt(rp contracts.RuningProcess) time.Time {
+	p, ok := rp.(*runingProcess)
+	if !ok {
+		return time.Unix(0, 0)
+	}
+
+	return p.currentRun().lastOutput()
+}
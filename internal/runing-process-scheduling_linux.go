@@ -0,0 +1,210 @@
+// +build linux
+
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+// from `sched.h` and `ioprio.h`, not in `x/sys`
+const (
+	schedOther       = 0
+	schedFIFO        = 1
+	schedRR          = 2
+	schedBatch       = 3
+	schedIdle        = 5
+	schedResetOnFork = 0x40000000
+
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioClassNone  = 0
+	ioprioClassRT    = 1
+	ioprioClassBE    = 2
+	ioprioClassIdle  = 3
+)
+
+var schedulingPolicies = map[string]int{
+	contracts.SchedulingPolicyOther: schedOther,
+	contracts.SchedulingPolicyFIFO:  schedFIFO,
+	contracts.SchedulingPolicyRR:    schedRR,
+	contracts.SchedulingPolicyBatch: schedBatch,
+	contracts.SchedulingPolicyIdle:  schedIdle,
+}
+
+var ioPriorityClasses = map[string]int{
+	contracts.IOPriorityClassNone:       ioprioClassNone,
+	contracts.IOPriorityClassRealtime:   ioprioClassRT,
+	contracts.IOPriorityClassBestEffort: ioprioClassBE,
+	contracts.IOPriorityClassIdle:       ioprioClassIdle,
+}
+
+// forEachThread - nice, affinity, policy and IO priority are per thread on Linux, a PID only reaches
+// the main thread, so like `taskset -a` the setting goes to every thread in `/proc/<pid>/task`.
+// Threads created while this runs can be missed, they inherit from the thread that created them
+func forEachThread(pid int, apply func(tid int) error) error {
+	if pid == 0 {
+		return apply(0)
+	}
+
+	fis, err := ioutil.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return apply(pid)
+	}
+
+	for _, fi := range fis {
+		tid, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+
+		// the thread exited since the folder was read
+		if err := apply(tid); err != nil && err != unix.ESRCH {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setProcessNice(pid int, nice int) error {
+	return forEachThread(pid, func(tid int) error {
+		return unix.Setpriority(unix.PRIO_PROCESS, tid, nice)
+	})
+}
+
+func setProcessCPUAffinity(pid int, cpus []int) error {
+	set := unix.CPUSet{}
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+
+	return forEachThread(pid, func(tid int) error {
+		return unix.SchedSetaffinity(tid, &set)
+	})
+}
+
+func setProcessSchedulingPolicy(pid int, policy string, priority int) error {
+	policyID, ok := schedulingPolicies[policy]
+	if !ok {
+		return fmt.Errorf("unknown scheduling policy [%s]", policy)
+	}
+
+	param := struct{ priority int32 }{priority: int32(priority)}
+	return forEachThread(pid, func(tid int) error {
+		_, _, errno := unix.Syscall(unix.SYS_SCHED_SETSCHEDULER, uintptr(tid), uintptr(policyID), uintptr(unsafe.Pointer(&param)))
+		if errno != 0 {
+			return errno
+		}
+
+		return nil
+	})
+}
+
+func setProcessIOPriority(pid int, class string, level int) error {
+	classID, ok := ioPriorityClasses[class]
+	if !ok {
+		return fmt.Errorf("unknown IO priority class [%s]", class)
+	}
+
+	return forEachThread(pid, func(tid int) error {
+		_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(classID<<ioprioClassShift|level))
+		if errno != 0 {
+			return errno
+		}
+
+		return nil
+	})
+}
+
+func setProcessOOMScoreAdj(pid int, score int) error {
+	return ioutil.WriteFile(fmt.Sprintf("/proc/%d/oom_score_adj", pid), []byte(strconv.Itoa(score)), 0644)
+}
+
+// applyThreadScheduling - nice, affinity, policy and IO priority are per thread on Linux and inherited
+// by children, so setting them on the thread that forks applies them before the child runs any code
+func applyThreadScheduling(scheduling *contracts.ProcessScheduling) error {
+	if scheduling.AppliesNice() {
+		if err := setProcessNice(0, scheduling.Nice); err != nil {
+			return err
+		}
+	}
+
+	if len(scheduling.CPUAffinity) > 0 {
+		if err := setProcessCPUAffinity(0, scheduling.CPUAffinity); err != nil {
+			return err
+		}
+	}
+
+	if len(scheduling.Policy) > 0 {
+		if err := setProcessSchedulingPolicy(0, scheduling.Policy, scheduling.Priority); err != nil {
+			return err
+		}
+	}
+
+	if len(scheduling.IOClass) > 0 {
+		if err := setProcessIOPriority(0, scheduling.IOClass, scheduling.IOLevel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyStartedScheduling - the OOM score is per process, so it can only be set once the child exists
+func applyStartedScheduling(pid int, scheduling *contracts.ProcessScheduling) error {
+	if scheduling == nil || !scheduling.AppliesOOMScoreAdj() {
+		return nil
+	}
+
+	return setProcessOOMScoreAdj(pid, scheduling.OOMScoreAdj)
+}
+
+// readProcScheduling - the policy and priorities come from `stat`, the rest needs a syscall or a file each
+func readProcScheduling(pid int, folder string, statFields []string, procMedata *contracts.RuntimeProcess) {
+	scheduling := &procMedata.Scheduling
+	scheduling.Nice = procMedata.CPU.Nice
+	scheduling.Priority, _ = strconv.Atoi(procStatField(statFields, 40))
+
+	policyID, _ := strconv.Atoi(procStatField(statFields, 41))
+	scheduling.Policy = ""
+	for name, id := range schedulingPolicies {
+		if id == policyID&^schedResetOnFork {
+			scheduling.Policy = name
+		}
+	}
+
+	scheduling.CPUAffinity = nil
+	set := unix.CPUSet{}
+	if unix.SchedGetaffinity(pid, &set) == nil {
+		scheduling.CPUAffinity = []int{}
+		for cpu := 0; cpu < len(set)*64; cpu++ {
+			if set.IsSet(cpu) {
+				scheduling.CPUAffinity = append(scheduling.CPUAffinity, cpu)
+			}
+		}
+	}
+
+	scheduling.IOClass = ""
+	ioprio, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno == 0 {
+		for name, id := range ioPriorityClasses {
+			if id == int(ioprio>>ioprioClassShift) {
+				scheduling.IOClass = name
+			}
+		}
+		scheduling.IOLevel = int(ioprio & (1<<ioprioClassShift - 1))
+	}
+
+	data, err := ioutil.ReadFile(path.Join(folder, "oom_score_adj"))
+	if err == nil {
+		scheduling.OOMScoreAdj, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+}
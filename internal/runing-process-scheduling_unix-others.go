@@ -0,0 +1,48 @@
+// +build !windows,!linux
+
+package internal
+
+import (
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+func setProcessNice(pid int, nice int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, pid, nice)
+}
+
+func setProcessCPUAffinity(pid int, cpus []int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessSchedulingPolicy(pid int, policy string, priority int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessIOPriority(pid int, class string, level int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessOOMScoreAdj(pid int, score int) error {
+	return contracts.ErrNotAvailable
+}
+
+// applyStartedScheduling - nice is per process here, so it is set on the child once it exists
+func applyStartedScheduling(pid int, scheduling *contracts.ProcessScheduling) error {
+	if scheduling == nil || !scheduling.AppliesNice() {
+		return nil
+	}
+
+	return setProcessNice(pid, scheduling.Nice)
+}
+
+// readProcessScheduling - only the nice value is known
+func readProcessScheduling(pid int, procMedata *contracts.RuntimeProcess) {
+	nice, err := unix.Getpriority(unix.PRIO_PROCESS, pid)
+	if err != nil {
+		return
+	}
+
+	procMedata.CPU.Nice = nice
+	procMedata.Scheduling.Nice = nice
+}
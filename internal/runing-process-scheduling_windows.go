@@ -0,0 +1,31 @@
+// +build windows
+
+package internal
+
+import (
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+func setProcessNice(pid int, nice int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessCPUAffinity(pid int, cpus []int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessSchedulingPolicy(pid int, policy string, priority int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessIOPriority(pid int, class string, level int) error {
+	return contracts.ErrNotAvailable
+}
+
+func setProcessOOMScoreAdj(pid int, score int) error {
+	return contracts.ErrNotAvailable
+}
+
+func applyStartedScheduling(pid int, scheduling *contracts.ProcessScheduling) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package internal
+
+import "syscall"
+
+func (thisRef *runingProcess) sendSignal(sig syscall.Signal) error {
+	return thisRef.signal(sig)
+}
+
+func (thisRef *runingProcess) pause() error {
+	return thisRef.signal(syscall.SIGSTOP)
+}
+
+func (thisRef *runingProcess) resume() error {
+	return thisRef.signal(syscall.SIGCONT)
+}
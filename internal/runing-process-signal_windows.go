@@ -0,0 +1,33 @@
+// +build windows
+
+package internal
+
+import (
+	"syscall"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// sendSignal - Windows has no signals, SIGINT is a CTRL_BREAK_EVENT to the process group of the
+// process, which it leads since it is spawned with CREATE_NEW_PROCESS_GROUP, and SIGKILL terminates it
+func (thisRef *runingProcess) sendSignal(sig syscall.Signal) error {
+	switch sig {
+	case syscall.SIGINT:
+		return sendCtrlBreak(thisRef.processID())
+	case syscall.SIGKILL:
+		return thisRef.signal(sig)
+	}
+
+	logging.Warningf("%s: signal [%s] is not supported on Windows", logID, sig)
+	return contracts.ErrSignalNotSupported
+}
+
+// pause - there is no console control event to suspend a process
+func (thisRef *runingProcess) pause() error {
+	return contracts.ErrSignalNotSupported
+}
+
+func (thisRef *runingProcess) resume() error {
+	return contracts.ErrSignalNotSupported
+}
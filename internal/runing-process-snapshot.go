@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// DefaultSnapshotWorkers - how many processes are read in parallel when no worker count is given
+func DefaultSnapshotWorkers() int {
+	workers := runtime.NumCPU()
+	if workers > 8 {
+		workers = 8
+	}
+
+	return workers
+}
+
+// SnapshotRuntimeProcesses - reads `fields` of all processes using at most `workers` goroutines,
+// returns the details and the fields that were read for each of them
+func SnapshotRuntimeProcesses(fields contracts.ProcessFields, workers int) ([]contracts.RuntimeProcess, []contracts.ProcessFields, error) {
+	return snapshotRuntimeProcesses(fields, workers)
+}
+
+// ReadRuntimeProcessesFields - reads the `fields` missing from `loaded` for each of `rps`, using at most `workers`
+// goroutines, processes that can't be read anymore are marked `ProcessStateNonExistent`
+func ReadRuntimeProcessesFields(rps []contracts.RuntimeProcess, loaded []contracts.ProcessFields, fields contracts.ProcessFields, workers int) {
+	if workers < 1 {
+		workers = DefaultSnapshotWorkers()
+	}
+
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				missing := fields &^ loaded[i]
+				if missing == contracts.ProcessFieldsNone {
+					continue
+				}
+
+				read, err := readRuntimeProcessFields(rps[i].ProcessID, &rps[i], missing)
+				if err != nil {
+					rps[i].State = contracts.ProcessStateNonExistent
+					continue
+				}
+
+				loaded[i] |= read
+			}
+		}()
+	}
+
+	for i := range rps {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
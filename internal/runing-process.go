@@ -28,7 +28,16 @@ type runingProcess struct {
 	isEmptyProcess  bool
 	stdOutPipe      io.ReadCloser
 	stdErrPipe      io.ReadCloser
+	stateSync       *sync.Mutex // guards what `Start()` replaces for each run, the command, handle, pipes, exit channel, times and `paused`
 	stopSync        *sync.Mutex
+	handle          *processHandle
+	isChild         bool
+	paused          bool // set by `Pause()`, cleared by `Resume()`
+	exited          chan struct{}
+	exitWatchSync   *sync.Once
+	run             *processRun
+	runSync         *sync.Mutex
+	tail            *outputTail
 }
 
 func newRuningProcess(processTemplate contracts.ProcessTemplate, isEmptyProcess bool) *runingProcess {
@@ -40,7 +49,16 @@ func newRuningProcess(processTemplate contracts.ProcessTemplate, isEmptyProcess
 		isEmptyProcess:  isEmptyProcess,
 		stdOutPipe:      nil,
 		stdErrPipe:      nil,
+		stateSync:       &sync.Mutex{},
 		stopSync:        &sync.Mutex{},
+		handle:          nil,
+		isChild:         false,
+		paused:          false,
+		exited:          make(chan struct{}),
+		exitWatchSync:   &sync.Once{},
+		run:             newProcessRun(),
+		runSync:         &sync.Mutex{},
+		tail:            newOutputTail(processTemplate.OutputTailLines),
 	}
 }
 
@@ -59,6 +77,7 @@ func NewRuningProcessWithOSProc(processTemplate contracts.ProcessTemplate, osPro
 	rp := newRuningProcess(processTemplate, false)
 	rp.osCmd = exec.Command(processTemplate.Executable, processTemplate.Args...)
 	rp.osCmd.Process = osProc
+	rp.handle = newProcessHandle(osProc)
 
 	return rp
 }
@@ -69,45 +88,61 @@ func (thisRef *runingProcess) Start() error {
 		return nil
 	}
 
-	thisRef.osCmd = exec.Command(thisRef.processTemplate.Executable, thisRef.processTemplate.Args...)
+	// the command is only published once it started, readers keep seeing the previous one until then
+	osCmd := exec.Command(thisRef.processTemplate.Executable, thisRef.processTemplate.Args...)
 
 	// set working folder
 	if !helpers.IsNullOrEmpty(thisRef.processTemplate.WorkingDirectory) {
-		thisRef.osCmd.Dir = thisRef.processTemplate.WorkingDirectory
+		osCmd.Dir = thisRef.processTemplate.WorkingDirectory
 	}
 
 	// set env
 	if thisRef.processTemplate.Environment != nil {
-		thisRef.osCmd.Env = thisRef.processTemplate.Environment
+		osCmd.Env = thisRef.processTemplate.Environment
 	}
 
 	var err error
 
+	osCmd.SysProcAttr, err = procAttrs(thisRef.processTemplate)
+	if err != nil {
+		detailedErr := fmt.Errorf("%s: start-FAILED %s, %s", logID, helpers.AsJSONString(thisRef.processTemplate), err.Error())
+		logging.Error(detailedErr.Error())
+
+		return detailedErr
+	}
+
+	run := newProcessRun()
+	thisRef.runSync.Lock()
+	thisRef.run = run
+	thisRef.runSync.Unlock()
+
+	// output is read without a reader too when it is watched for stalls or kept
+	watchOutput := thisRef.processTemplate.NoOutputTimeout > 0 || thisRef.processTemplate.OutputTailLines > 0
+
 	// capture STDOUT
-	if thisRef.processTemplate.StdoutReader != nil {
-		thisRef.stdOutPipe, err = thisRef.osCmd.StdoutPipe()
+	var stdOutPipe io.ReadCloser
+	if thisRef.processTemplate.StdoutReader != nil || watchOutput {
+		stdOutPipe, err = osCmd.StdoutPipe()
 		if err != nil {
 			logging.Errorf("%s: get-StdOut-FAIL for [%s], [%s]", logID, thisRef.processTemplate.Executable, err.Error())
 			return err
 		}
 
+		// the reader only touches its own pipe, `Stop()` may close it at any time
 		go func() {
 			logging.Debugf("%s: read-STDOUT for [%s]", logID, thisRef.processTemplate.Executable)
-			err := readOutput(thisRef.stdOutPipe, thisRef.processTemplate.StdoutReader, thisRef.processTemplate.StdoutReaderParams)
+			err := readOutput(stdOutPipe, thisRef.processTemplate.StdoutReader, thisRef.processTemplate.StdoutReaderParams, thisRef.outputHandler(run, contracts.OutputStreamStdout))
 			if err != nil {
 				logging.Warningf("%s: read-STDOUT-FAIL for [%s], [%s]", logID, thisRef.processTemplate.Executable, err.Error())
 			}
 			logging.Debugf("%s: read-STDOUT-SUCCESS for [%s]", logID, thisRef.processTemplate.Executable)
-
-			thisRef.processTemplate.StdoutReader = nil
-			thisRef.processTemplate.StdoutReaderParams = nil
-			thisRef.stdOutPipe = nil
 		}()
 	}
 
 	// capture STDERR
-	if thisRef.processTemplate.StderrReader != nil {
-		thisRef.stdErrPipe, err = thisRef.osCmd.StderrPipe()
+	var stdErrPipe io.ReadCloser
+	if thisRef.processTemplate.StderrReader != nil || watchOutput {
+		stdErrPipe, err = osCmd.StderrPipe()
 		if err != nil {
 			logging.Errorf("%s: get-StdErr-FAIL for [%s], [%s]", logID, thisRef.processTemplate.Executable, err.Error())
 			return err
@@ -115,26 +150,20 @@ func (thisRef *runingProcess) Start() error {
 
 		go func() {
 			logging.Debugf("%s: read-STDERR for [%s]", logID, thisRef.processTemplate.Executable)
-			err := readOutput(thisRef.stdErrPipe, thisRef.processTemplate.StderrReader, thisRef.processTemplate.StderrReaderParams)
+			err := readOutput(stdErrPipe, thisRef.processTemplate.StderrReader, thisRef.processTemplate.StderrReaderParams, thisRef.outputHandler(run, contracts.OutputStreamStderr))
 			if err != nil {
 				logging.Warningf("%s: read-STDERR-FAIL for [%s], [%s]", logID, thisRef.processTemplate.Executable, err.Error())
 			}
 			logging.Debugf("%s: read-STDERR-SUCCESS for [%s]", logID, thisRef.processTemplate.Executable)
-
-			thisRef.processTemplate.StderrReader = nil
-			thisRef.processTemplate.StderrReaderParams = nil
-			thisRef.stdErrPipe = nil
 		}()
 	}
 
-	// thisRef.osCmd.SysProcAttr = procAttrs
-
 	// start
 	logging.Debugf("%s: start %s", logID, helpers.AsJSONString(thisRef.processTemplate))
 
-	err = thisRef.osCmd.Start()
+	cleanup, err := startCommand(osCmd, thisRef.processTemplate)
 	if err != nil {
-		thisRef.stoppedAt = time.Now()
+		thisRef.markStopped()
 
 		detailedErr := fmt.Errorf("%s: start-FAILED %s, %s", logID, helpers.AsJSONString(thisRef.processTemplate), err.Error())
 		logging.Error(detailedErr.Error())
@@ -142,15 +171,42 @@ func (thisRef *runingProcess) Start() error {
 		return detailedErr
 	}
 
-	thisRef.startedAt = time.Now()
+	startedAt := time.Now()
+
+	// pin the child before anyone can reap it, from here on its PID can't be confused with a recycled one
+	exited := make(chan struct{})
+	handle := newProcessHandle(osCmd.Process)
+	handle.pin()
+	run.started(osCmd.Process.Pid, startedAt, exited)
+
+	thisRef.stateSync.Lock()
+	thisRef.osCmd = osCmd
+	thisRef.handle = handle
+	thisRef.isChild = true
+	thisRef.paused = false
+	thisRef.exited = exited
+	thisRef.startedAt = startedAt
+	thisRef.stdOutPipe = stdOutPipe
+	thisRef.stdErrPipe = stdErrPipe
+	thisRef.stateSync.Unlock()
+
+	err = applyStartedScheduling(osCmd.Process.Pid, thisRef.processTemplate.Scheduling)
+	if err != nil {
+		logging.Warningf("%s: scheduling-FAIL for [%s], [%s]", logID, thisRef.processTemplate.Executable, err.Error())
+	}
 
 	// wait for process exit - either when it gets killed externally or by calling `.Stop()`
 	go func() {
-		thisRef.osCmd.Process.Wait()
+		state, _ := osCmd.Process.Wait()
+		handle.release()
+		run.markExited(state)
+		cleanup()
 
 		if thisRef.processTemplate.OnStopped != nil {
 			thisRef.processTemplate.OnStopped(thisRef.processTemplate.OnStoppedParams)
 		}
+
+		close(exited)
 	}()
 
 	return nil
@@ -161,15 +217,27 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 	thisRef.stopSync.Lock()
 	defer thisRef.stopSync.Unlock()
 
-	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
+	osCmd, _ := thisRef.command()
+	if osCmd == nil || osCmd.Process == nil {
 		return nil
 	}
 
-	if thisRef.stdOutPipe != nil {
-		thisRef.stdOutPipe.Close()
+	thisRef.currentRun().setExitReason(contracts.ExitReasonStopped)
+
+	thisRef.stateSync.Lock()
+	stdOutPipe, stdErrPipe, paused := thisRef.stdOutPipe, thisRef.stdErrPipe, thisRef.paused
+	thisRef.stateSync.Unlock()
+
+	// a process paused by `Pause()` only reacts to SIGKILL, one stopped by someone else is left as is
+	if paused {
+		thisRef.Resume()
 	}
-	if thisRef.stdErrPipe != nil {
-		thisRef.stdErrPipe.Close()
+
+	if stdOutPipe != nil {
+		stdOutPipe.Close()
+	}
+	if stdErrPipe != nil {
+		stdErrPipe.Close()
 	}
 
 	defer func() {
@@ -193,12 +261,12 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 
 		for i := 0; i < attempts; i++ {
 			logging.Debugf("%s: stop-ATTEMPT-SIGINT #%d to stop [%s]", logID, i, thisRef.processTemplate.Executable)
-			thisRef.osCmd.Process.Signal(syscall.SIGINT) // this works on all except on Windows
+			thisRef.signal(syscall.SIGINT) // this works on all except on Windows
 			time.Sleep(waitTimeout)
 
-			if thisRef.osCmd.ProcessState != nil && thisRef.osCmd.ProcessState.Exited() {
-				thisRef.osCmd.Process.Wait()
-				thisRef.stoppedAt = time.Now()
+			if osCmd.ProcessState != nil && osCmd.ProcessState.Exited() {
+				osCmd.Process.Wait()
+				thisRef.markStopped()
 				logging.Debugf("%s: stop-SUCCESS [%s]", logID, thisRef.processTemplate.Executable)
 				return nil
 			}
@@ -206,11 +274,11 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 
 		for i := 0; i < attempts; i++ {
 			logging.Debugf("%s: stop-ATTEMPT-SIGTERM #%d to stop [%s]", logID, i, thisRef.processTemplate.Executable)
-			thisRef.osCmd.Process.Signal(syscall.SIGTERM)
+			thisRef.signal(syscall.SIGTERM)
 			time.Sleep(waitTimeout)
 
 			if !thisRef.IsRunning() {
-				thisRef.stoppedAt = time.Now()
+				thisRef.markStopped()
 				logging.Debugf("%s: stop-SUCCESS [%s]", logID, thisRef.processTemplate.Executable)
 				return nil
 			}
@@ -218,11 +286,11 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 
 		for i := 0; i < attempts; i++ {
 			logging.Debugf("%s: stop-ATTEMPT-SIGKILL #%d to stop [%s]", logID, i, thisRef.processTemplate.Executable)
-			thisRef.osCmd.Process.Signal(syscall.SIGKILL)
+			thisRef.signal(syscall.SIGKILL)
 			time.Sleep(waitTimeout)
 
 			if !thisRef.IsRunning() {
-				thisRef.stoppedAt = time.Now()
+				thisRef.markStopped()
 				logging.Debugf("%s: stop-SUCCESS [%s]", logID, thisRef.processTemplate.Executable)
 				return nil
 			}
@@ -230,11 +298,11 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 
 		for i := 0; i < attempts; i++ {
 			logging.Debugf("%s: stop-ATTEMPT-aggressive-kill-1 #%d to stop [%s]", logID, i, thisRef.processTemplate.Executable)
-			processKillHelper(thisRef.osCmd.Process.Pid)
+			processKillHelper(osCmd.Process.Pid)
 			time.Sleep(waitTimeout)
 
 			if !thisRef.IsRunning() {
-				thisRef.stoppedAt = time.Now()
+				thisRef.markStopped()
 				logging.Debugf("%s: stop-SUCCESS [%s]", logID, thisRef.processTemplate.Executable)
 				return nil
 			}
@@ -242,11 +310,11 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 
 		for i := 0; i < attempts; i++ {
 			logging.Debugf("%s: stop-ATTEMPT-aggressive-kill-2 #%d to stop [%s]", logID, i, thisRef.processTemplate.Executable)
-			err = thisRef.osCmd.Process.Kill()
+			err = osCmd.Process.Kill()
 			time.Sleep(waitTimeout)
 
 			if !thisRef.IsRunning() {
-				thisRef.stoppedAt = time.Now()
+				thisRef.markStopped()
 				logging.Debugf("%s: stop-SUCCESS [%s]", logID, thisRef.processTemplate.Executable)
 				return nil
 			}
@@ -257,13 +325,13 @@ func (thisRef *runingProcess) Stop(tag string, attempts int, waitTimeout time.Du
 }
 
 // IsRunning - tells if the process is running
-func (thisRef runingProcess) IsRunning() bool {
-	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
+func (thisRef *runingProcess) IsRunning() bool {
+	osCmd, handle := thisRef.command()
+	if osCmd == nil || osCmd.Process == nil {
 		return false
 	}
 
-	pid := thisRef.processID()
-	if pid == processDoesNotExist {
+	if handle != nil && !handle.isAlive() {
 		return false
 	}
 
@@ -276,34 +344,127 @@ func (thisRef runingProcess) IsRunning() bool {
 }
 
 // Details - return processTemplate about the process
-func (thisRef runingProcess) Details() contracts.RuntimeProcess {
-	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
+func (thisRef *runingProcess) Details() contracts.RuntimeProcess {
+	osCmd, handle := thisRef.command()
+	if osCmd == nil || osCmd.Process == nil {
 		return contracts.RuntimeProcess{
 			State: contracts.ProcessStateNonExistent,
 		}
 	}
 
-	rpByPID, err := getRuntimeProcessByPID(thisRef.processID())
+	if handle != nil && !handle.isAlive() {
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateNonExistent,
+		}
+	}
+
+	rpByPID, err := getRuntimeProcessByPID(osCmd.Process.Pid)
 	if err != nil {
 		return contracts.RuntimeProcess{
 			State: contracts.ProcessStateNonExistent,
 		}
 	}
 
+	// the process might have exited while it was being read and its PID handed to someone else
+	if handle != nil && !handle.isAlive() {
+		return contracts.RuntimeProcess{
+			State: contracts.ProcessStateNonExistent,
+		}
+	}
+
 	return rpByPID
 }
 
-// ExitCode -
-func (thisRef runingProcess) ExitCode() int {
-	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil || thisRef.osCmd.ProcessState == nil {
-		return 0
+// Exited - returns a channel that gets closed when the process exits, works for children and non-children
+func (thisRef *runingProcess) Exited() <-chan struct{} {
+	thisRef.stateSync.Lock()
+	handle, isChild, exited := thisRef.handle, thisRef.isChild, thisRef.exited
+	thisRef.stateSync.Unlock()
+
+	if thisRef.isEmptyProcess || handle == nil {
+		closedChannel := make(chan struct{})
+		close(closedChannel)
+		return closedChannel
+	}
+
+	// children are reaped by the goroutine started in `.Start()`, everyone else is watched on demand
+	if !isChild {
+		thisRef.exitWatchSync.Do(func() {
+			go func() {
+				handle.wait()
+				close(exited)
+			}()
+		})
+	}
+
+	return exited
+}
+
+// SetNice - changes the nice value, -20 (favorable) to 19, on Linux for every thread of the process
+func (thisRef *runingProcess) SetNice(nice int) error {
+	pid, err := thisRef.liveProcessID()
+	if err != nil {
+		return err
+	}
+
+	return setProcessNice(pid, nice)
+}
+
+// SetCPUAffinity - restricts every thread of the process to `cpus`, Linux only
+func (thisRef *runingProcess) SetCPUAffinity(cpus []int) error {
+	pid, err := thisRef.liveProcessID()
+	if err != nil {
+		return err
+	}
+
+	return setProcessCPUAffinity(pid, cpus)
+}
+
+// SetSchedulingPolicy - changes the policy of every thread to one of the `contracts.SchedulingPolicy*`, Linux only
+func (thisRef *runingProcess) SetSchedulingPolicy(policy string, priority int) error {
+	pid, err := thisRef.liveProcessID()
+	if err != nil {
+		return err
+	}
+
+	return setProcessSchedulingPolicy(pid, policy, priority)
+}
+
+// SetIOPriority - changes the IO class of every thread to one of the `contracts.IOPriorityClass*`, Linux only
+func (thisRef *runingProcess) SetIOPriority(class string, level int) error {
+	pid, err := thisRef.liveProcessID()
+	if err != nil {
+		return err
 	}
 
-	return thisRef.osCmd.ProcessState.ExitCode()
+	return setProcessIOPriority(pid, class, level)
+}
+
+// SetOOMScoreAdj - changes how likely the OOM killer picks the process, Linux only
+func (thisRef *runingProcess) SetOOMScoreAdj(score int) error {
+	pid, err := thisRef.liveProcessID()
+	if err != nil {
+		return err
+	}
+
+	return setProcessOOMScoreAdj(pid, score)
+}
+
+// ExitCode -
+func (thisRef *runingProcess) ExitCode() int {
+	return thisRef.currentRun().result().ExitCode
+}
+
+// ExitResult - how the last run ended, the reason is empty while it runs
+func (thisRef *runingProcess) ExitResult() contracts.ProcessExitResult {
+	return thisRef.currentRun().result()
 }
 
 // StartedAt - returns the time when the process was started
-func (thisRef runingProcess) StartedAt() time.Time {
+func (thisRef *runingProcess) StartedAt() time.Time {
+	thisRef.stateSync.Lock()
+	defer thisRef.stateSync.Unlock()
+
 	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
 		return time.Unix(0, 0)
 	}
@@ -312,7 +473,10 @@ func (thisRef runingProcess) StartedAt() time.Time {
 }
 
 // StoppedAt - returns the time when the process was stopped
-func (thisRef runingProcess) StoppedAt() time.Time {
+func (thisRef *runingProcess) StoppedAt() time.Time {
+	thisRef.stateSync.Lock()
+	defer thisRef.stateSync.Unlock()
+
 	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
 		return time.Unix(0, 0)
 	}
@@ -320,15 +484,110 @@ func (thisRef runingProcess) StoppedAt() time.Time {
 	return thisRef.stoppedAt
 }
 
-func (thisRef runingProcess) processID() int {
-	if thisRef.osCmd == nil || thisRef.osCmd.Process == nil {
+func (thisRef *runingProcess) markStopped() {
+	thisRef.stateSync.Lock()
+	thisRef.stoppedAt = time.Now()
+	thisRef.stateSync.Unlock()
+}
+
+// command - the command and handle of the latest start, `Start()` replaces both
+func (thisRef *runingProcess) command() (*exec.Cmd, *processHandle) {
+	thisRef.stateSync.Lock()
+	defer thisRef.stateSync.Unlock()
+
+	return thisRef.osCmd, thisRef.handle
+}
+
+// Signal - sends `sig` through the process handle, so never to a process that reused the PID
+func (thisRef *runingProcess) Signal(sig syscall.Signal) error {
+	if _, err := thisRef.liveProcessID(); err != nil {
+		return err
+	}
+
+	return thisRef.sendSignal(sig)
+}
+
+// Pause - suspends the process, its state reads as `ProcessStateTraced` until `Resume()`
+func (thisRef *runingProcess) Pause() error {
+	if _, err := thisRef.liveProcessID(); err != nil {
+		return err
+	}
+
+	if err := thisRef.pause(); err != nil {
+		return err
+	}
+
+	thisRef.setPaused(true)
+	return nil
+}
+
+// Resume - continues a process suspended by `Pause()`
+func (thisRef *runingProcess) Resume() error {
+	if _, err := thisRef.liveProcessID(); err != nil {
+		return err
+	}
+
+	if err := thisRef.resume(); err != nil {
+		return err
+	}
+
+	thisRef.setPaused(false)
+	return nil
+}
+
+func (thisRef *runingProcess) setPaused(paused bool) {
+	thisRef.stateSync.Lock()
+	thisRef.paused = paused
+	thisRef.stateSync.Unlock()
+}
+
+// Reload - sends the `ReloadSignal` of the template, SIGHUP if it has none
+func (thisRef *runingProcess) Reload() error {
+	sig := thisRef.processTemplate.ReloadSignal
+	if sig == 0 {
+		sig = syscall.SIGHUP
+	}
+
+	return thisRef.Signal(sig)
+}
+
+func (thisRef *runingProcess) signal(sig syscall.Signal) error {
+	osCmd, handle := thisRef.command()
+	if osCmd == nil || osCmd.Process == nil {
+		return contracts.ErrProcessDoesNotExist
+	}
+
+	if handle == nil {
+		return osCmd.Process.Signal(sig)
+	}
+
+	return handle.signal(sig)
+}
+
+// liveProcessID - the PID, as long as it still belongs to this process
+func (thisRef *runingProcess) liveProcessID() (int, error) {
+	osCmd, handle := thisRef.command()
+	if thisRef.isEmptyProcess || osCmd == nil || osCmd.Process == nil {
+		return processDoesNotExist, contracts.ErrProcessDoesNotExist
+	}
+
+	if handle != nil && !handle.isAlive() {
+		return processDoesNotExist, contracts.ErrProcessDoesNotExist
+	}
+
+	return osCmd.Process.Pid, nil
+}
+
+func (thisRef *runingProcess) processID() int {
+	osCmd, _ := thisRef.command()
+	if osCmd == nil || osCmd.Process == nil {
 		return processDoesNotExist
 	}
 
-	return thisRef.osCmd.Process.Pid
+	return osCmd.Process.Pid
 }
 
-func readOutput(readerCloser io.ReadCloser, outputReader contracts.ProcessOutputReader, params interface{}) error {
+func readOutput(readerCloser io.ReadCloser, outputReader contracts.ProcessOutputReader, params interface{}, onOutput func(line []byte)) error {
 	reader := bufio.NewReader(readerCloser)
 	line, _, err := reader.ReadLine()
 	for {
@@ -336,6 +595,8 @@ func readOutput(readerCloser io.ReadCloser, outputReader contracts.ProcessOutput
 			break
 		}
 
+		onOutput(line)
+
 		if outputReader != nil {
 			outputReader(params, line)
 		}
@@ -0,0 +1,24 @@
+// +build darwin freebsd
+
+package internal
+
+import (
+	"runtime"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"golang.org/x/sys/unix"
+)
+
+// GetSystemCPUTicks - not exposed as ticks on this platform, callers fall back to wall-clock time
+func GetSystemCPUTicks() (uint64, int, error) {
+	return 0, runtime.NumCPU(), contracts.ErrNotAvailable
+}
+
+// GetSystemMemoryTotal - physical memory in bytes
+func GetSystemMemoryTotal() (uint64, error) {
+	if runtime.GOOS == "darwin" {
+		return unix.SysctlUint64("hw.memsize")
+	}
+
+	return unix.SysctlUint64("hw.physmem")
+}
@@ -0,0 +1,56 @@
+// +build linux
+
+package internal
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// GetSystemCPUTicks - total CPU time of all CPUs since boot in clock ticks, and the number of CPUs
+func GetSystemCPUTicks() (uint64, int, error) {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	total := uint64(0)
+	numCPU := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		if fields[0] != "cpu" {
+			numCPU++
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal, guest time is already part of user and nice
+		for i := 1; i < len(fields) && i <= 8; i++ {
+			ticks, _ := strconv.ParseUint(fields[i], 10, 64)
+			total += ticks
+		}
+	}
+
+	return total, numCPU, nil
+}
+
+// GetSystemMemoryTotal - physical memory in bytes
+func GetSystemMemoryTotal() (uint64, error) {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		props := strings.Split(line, ":")
+		if len(props) > 1 && props[0] == "MemTotal" {
+			return parseProcStatusKB(props[1]), nil
+		}
+	}
+
+	return 0, nil
+}
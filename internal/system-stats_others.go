@@ -0,0 +1,19 @@
+// +build !linux,!darwin,!freebsd
+
+package internal
+
+import (
+	"runtime"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// GetSystemCPUTicks - not exposed as ticks on this platform, callers fall back to wall-clock time
+func GetSystemCPUTicks() (uint64, int, error) {
+	return 0, runtime.NumCPU(), contracts.ErrNotAvailable
+}
+
+// GetSystemMemoryTotal - not available on this platform
+func GetSystemMemoryTotal() (uint64, error) {
+	return 0, contracts.ErrNotAvailable
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+const logID = "PROCESS-MONITOR-API"
+
+// Networks the API listens on
+const (
+	NetworkUnix = "unix" // a Unix domain socket, access is controlled by its file permissions
+	NetworkTCP  = "tcp"  // a loopback address, access is controlled by the bearer token
+)
+
+// ErrNotLoopback - TCP addresses have to be loopback ones, the API is for the local box only
+var ErrNotLoopback = errors.New("ErrNotLoopback")
+
+// ErrTokenRequired - anyone on the box can connect to a loopback port, so TCP needs a token
+var ErrTokenRequired = errors.New("ErrTokenRequired")
+
+// defaultSocketMode - only the owner of the monitor can use the socket
+const defaultSocketMode = 0600
+
+// readHeaderTimeout - a client that never finishes its headers doesn't hold a connection forever,
+// there is no overall timeout since event streams stay open
+const readHeaderTimeout = 10 * time.Second
+
+// Options - where and how the API is served
+type Options struct {
+	Network    string      // `NetworkUnix` or `NetworkTCP`
+	Address    string      // the socket path, or a loopback `host:port`
+	SocketMode os.FileMode // permissions of the socket, 0600 if not set
+	Token      string      // clients send `Authorization: Bearer <token>`, required for TCP, optional for a socket
+}
+
+// Server - REST control API for a monitor
+type Server struct {
+	monitor    contracts.Monitor
+	options    Options
+	listener   net.Listener
+	httpServer *http.Server
+	serverSync *sync.Mutex
+}
+
+// New - an API for `monitor`, `Start()` serves it
+func New(monitor contracts.Monitor, options Options) *Server {
+	if options.SocketMode == 0 {
+		options.SocketMode = defaultSocketMode
+	}
+
+	return &Server{
+		monitor:    monitor,
+		options:    options,
+		listener:   nil,
+		httpServer: nil,
+		serverSync: &sync.Mutex{},
+	}
+}
+
+// Start - listens and serves in the background until `Stop()`
+func (thisRef *Server) Start() error {
+	thisRef.serverSync.Lock()
+	defer thisRef.serverSync.Unlock()
+
+	if thisRef.listener != nil {
+		return nil
+	}
+
+	listener, err := thisRef.listen()
+	if err != nil {
+		return err
+	}
+
+	thisRef.listener = listener
+	thisRef.httpServer = &http.Server{
+		Handler:           thisRef.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	logging.Infof("%s: serving on %s [%s]", logID, thisRef.options.Network, listener.Addr().String())
+
+	go func(httpServer *http.Server) {
+		if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("%s: serve-FAIL, [%s]", logID, err.Error())
+		}
+	}(thisRef.httpServer)
+
+	return nil
+}
+
+// Stop - closes the listener and all connections, event streams included
+func (thisRef *Server) Stop() error {
+	thisRef.serverSync.Lock()
+	defer thisRef.serverSync.Unlock()
+
+	if thisRef.listener == nil {
+		return nil
+	}
+
+	err := thisRef.httpServer.Close()
+	if thisRef.options.Network == NetworkUnix {
+		os.Remove(thisRef.options.Address)
+	}
+
+	thisRef.listener = nil
+	thisRef.httpServer = nil
+
+	return err
+}
+
+// Addr - where the API listens, useful with port 0, `nil` before `Start()`
+func (thisRef *Server) Addr() net.Addr {
+	thisRef.serverSync.Lock()
+	defer thisRef.serverSync.Unlock()
+
+	if thisRef.listener == nil {
+		return nil
+	}
+
+	return thisRef.listener.Addr()
+}
+
+// Handler - the API as a handler, with auth, for serving it some other way
+func (thisRef *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !thisRef.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or bad bearer token"))
+			return
+		}
+
+		thisRef.route(w, r)
+	})
+}
+
+func (thisRef *Server) listen() (net.Listener, error) {
+	switch thisRef.options.Network {
+	case NetworkUnix:
+		// a socket left by a previous run blocks the address
+		if info, err := os.Stat(thisRef.options.Address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(thisRef.options.Address)
+		}
+
+		listener, err := listenUnixSocket(thisRef.options.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.Chmod(thisRef.options.Address, thisRef.options.SocketMode); err != nil {
+			listener.Close()
+			return nil, err
+		}
+
+		return listener, nil
+
+	case NetworkTCP:
+		if len(thisRef.options.Token) == 0 {
+			return nil, ErrTokenRequired
+		}
+
+		if !isLoopback(thisRef.options.Address) {
+			return nil, ErrNotLoopback
+		}
+
+		return net.Listen(NetworkTCP, thisRef.options.Address)
+	}
+
+	return nil, fmt.Errorf("bad network [%s], use [%s] or [%s]", thisRef.options.Network, NetworkUnix, NetworkTCP)
+}
+
+func (thisRef *Server) authorized(r *http.Request) bool {
+	if len(thisRef.options.Token) == 0 {
+		return true
+	}
+
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, prefix)), []byte(thisRef.options.Token)) == 1
+}
+
+// isLoopback - `localhost` or a loopback IP, a host that is left out means all interfaces
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
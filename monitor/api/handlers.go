@@ -0,0 +1,282 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// defaultOutputLines - lines `/output` returns without `?lines=`
+const defaultOutputLines = 100
+
+// tagStatus - what the API reports for a tag
+type tagStatus struct {
+	Tag     string                        `json:"tag"`
+	Labels  map[string]string             `json:"labels"`
+	Running bool                          `json:"running"`
+	Details *contracts.RuntimeProcess     `json:"details,omitempty"` // only while running
+	LastRun contracts.ProcessExitResult   `json:"lastRun"`
+	History []contracts.ProcessExitResult `json:"history,omitempty"` // only for a single tag
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// route - the API routes:
+//
+//	GET  /tags[?selector=]                    all tags, optionally matching a label selector
+//	GET  /tags/<tag>                          one tag with its run history
+//	POST /tags/<tag>/<action>                 start, stop, restart, pause, resume or reload
+//	POST /tags/<tag>/signal?signal=<sig>      sends a signal by name like `HUP` or number
+//	GET  /tags/<tag>/output[?lines=]          the last lines of output
+//	GET  /events[?tag=]                       server-sent events
+func (thisRef *Server) route(w http.ResponseWriter, r *http.Request) {
+	segments, err := pathSegments(r.URL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && segments[0] == "tags":
+		if requireMethod(w, r, http.MethodGet) {
+			thisRef.listTags(w, r)
+		}
+
+	case len(segments) == 1 && segments[0] == "events":
+		if requireMethod(w, r, http.MethodGet) {
+			thisRef.streamEvents(w, r)
+		}
+
+	case len(segments) >= 2 && len(segments) <= 3 && segments[0] == "tags":
+		tag := segments[1]
+		if !thisRef.isMonitored(tag) {
+			writeError(w, http.StatusNotFound, fmt.Errorf("tag [%s] is not monitored", tag))
+			return
+		}
+
+		if len(segments) == 2 {
+			if requireMethod(w, r, http.MethodGet) {
+				writeJSON(w, http.StatusOK, thisRef.status(tag, true))
+			}
+			return
+		}
+
+		if segments[2] == "output" {
+			if requireMethod(w, r, http.MethodGet) {
+				thisRef.output(w, r, tag)
+			}
+			return
+		}
+
+		if requireMethod(w, r, http.MethodPost) {
+			thisRef.action(w, r, tag, segments[2])
+		}
+
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for [%s]", r.URL.Path))
+	}
+}
+
+func (thisRef *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := thisRef.monitor.Select(r.URL.Query().Get("selector"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	statuses := make([]tagStatus, 0, len(tags))
+	for _, tag := range tags {
+		statuses = append(statuses, thisRef.status(tag, false))
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (thisRef *Server) status(tag string, withHistory bool) tagStatus {
+	rp := thisRef.monitor.GetProcess(tag)
+
+	status := tagStatus{
+		Tag:     tag,
+		Labels:  thisRef.monitor.Labels(tag),
+		Running: rp.IsRunning(),
+		LastRun: rp.ExitResult(),
+	}
+
+	if status.Running {
+		details := rp.Details()
+		status.Details = &details
+	}
+
+	if withHistory {
+		status.History = thisRef.monitor.History(tag)
+	}
+
+	return status
+}
+
+func (thisRef *Server) action(w http.ResponseWriter, r *http.Request, tag string, action string) {
+	logging.Debugf("%s: %s %s", logID, action, tag)
+
+	var err error
+	switch action {
+	case "start":
+		err = thisRef.monitor.Start(tag)
+	case "stop":
+		err = thisRef.monitor.Stop(tag)
+	case "restart":
+		err = thisRef.monitor.Restart(tag)
+	case "pause":
+		err = thisRef.monitor.Pause(tag)
+	case "resume":
+		err = thisRef.monitor.Resume(tag)
+	case "reload":
+		err = thisRef.monitor.Reload(tag)
+	case "signal":
+		sig, parseErr := parseSignal(r.URL.Query().Get("signal"))
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, parseErr)
+			return
+		}
+
+		err = thisRef.monitor.Signal(tag, sig)
+
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action [%s]", action))
+		return
+	}
+
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, thisRef.status(tag, false))
+}
+
+func (thisRef *Server) output(w http.ResponseWriter, r *http.Request, tag string) {
+	lines := defaultOutputLines
+	if value := r.URL.Query().Get("lines"); len(value) > 0 {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad line count [%s]", value))
+			return
+		}
+
+		lines = parsed
+	}
+
+	writeJSON(w, http.StatusOK, thisRef.monitor.Output(tag, lines))
+}
+
+// streamEvents - each monitor event as an SSE `event: <type>` with the JSON event as data, until the client goes away
+func (thisRef *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	tag := r.URL.Query().Get("tag")
+	events := thisRef.monitor.Events(r.Context())
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for event := range events {
+		if len(tag) > 0 && event.Tag != tag {
+			continue
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (thisRef *Server) isMonitored(tag string) bool {
+	tags := thisRef.monitor.GetAllTags()
+	sort.Strings(tags)
+
+	i := sort.SearchStrings(tags, tag)
+	return i < len(tags) && tags[i] == tag
+}
+
+// pathSegments - the unescaped parts of the path, so tags can have slashes as `%2F`
+func pathSegments(u *url.URL) ([]string, error) {
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(u.EscapedPath(), "/"), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, unescaped)
+	}
+
+	return segments, nil
+}
+
+// parseSignal - a name like `HUP` or `SIGHUP`, or a number
+func parseSignal(value string) (syscall.Signal, error) {
+	if number, err := strconv.Atoi(value); err == nil && number > 0 {
+		return syscall.Signal(number), nil
+	}
+
+	if sig, ok := signalsByName[strings.TrimPrefix(strings.ToUpper(value), "SIG")]; ok {
+		return sig, nil
+	}
+
+	return 0, fmt.Errorf("bad signal [%s]", value)
+}
+
+func statusForError(err error) int {
+	switch err {
+	case contracts.ErrProcessDoesNotExist:
+		return http.StatusConflict
+	case contracts.ErrSignalNotSupported, contracts.ErrNotAvailable:
+		return http.StatusNotImplemented
+	}
+
+	return http.StatusInternalServerError
+}
+
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use [%s]", method))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logging.Warningf("%s: write-FAIL, [%s]", logID, err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
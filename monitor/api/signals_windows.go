@@ -0,0 +1,14 @@
+// +build windows
+
+package api
+
+import "syscall"
+
+// signalsByName - the signals that can be sent by name, of these only INT and KILL get through on Windows
+var signalsByName = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
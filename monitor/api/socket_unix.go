@@ -0,0 +1,24 @@
+// +build !windows
+
+package api
+
+import (
+	"net"
+	"sync"
+	"syscall"
+)
+
+// umaskSync - the umask is process wide, concurrent listens must not restore each other's
+var umaskSync = &sync.Mutex{}
+
+// listenUnixSocket - creates the socket with no permissions for group and others, so nobody can connect
+// before `SocketMode` is applied, files other goroutines create meanwhile come out just as restricted
+func listenUnixSocket(address string) (net.Listener, error) {
+	umaskSync.Lock()
+	defer umaskSync.Unlock()
+
+	previous := syscall.Umask(0177)
+	defer syscall.Umask(previous)
+
+	return net.Listen(NetworkUnix, address)
+}
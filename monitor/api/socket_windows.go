@@ -0,0 +1,10 @@
+// +build windows
+
+package api
+
+import "net"
+
+// listenUnixSocket - Windows has no umask, the socket gets the ACL of its folder
+func listenUnixSocket(address string) (net.Listener, error) {
+	return net.Listen(NetworkUnix, address)
+}
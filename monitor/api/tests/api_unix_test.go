@@ -0,0 +1,163 @@
+// +build !windows
+
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+	"github.com/remoteit/systemkit-processes/monitor/api"
+)
+
+func TestAPIOverSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	monitor := procMon.New()
+	monitor.SpawnWithTag(contracts.ProcessTemplate{
+		Executable:      "sh",
+		Args:            []string{"-c", "echo one; echo two >&2; while true; do sleep 0.05; done"},
+		Labels:          map[string]string{"app": "worker"},
+		OutputTailLines: 10,
+	}, "worker")
+	defer monitor.StopSelected("")
+
+	socket := filepath.Join(dir, "api.sock")
+	server := api.New(monitor, api.Options{Network: api.NetworkUnix, Address: socket})
+	if err := server.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer server.Stop()
+
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("bad socket: %v, %v", info, err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, api.NetworkUnix, socket)
+		},
+	}}
+
+	time.Sleep(200 * time.Millisecond)
+
+	var tags []struct {
+		Tag     string                    `json:"tag"`
+		Running bool                      `json:"running"`
+		Details *contracts.RuntimeProcess `json:"details"`
+	}
+	if status := request(t, client, http.MethodGet, "http://api/tags?selector=app%3Dworker", "", &tags); status != http.StatusOK {
+		t.Fatalf("bad: %d", status)
+	}
+	if len(tags) != 1 || tags[0].Tag != "worker" || !tags[0].Running || tags[0].Details == nil || tags[0].Details.ProcessID == 0 {
+		t.Fatalf("bad: %#v", tags)
+	}
+
+	var output []contracts.ProcessOutputLine
+	request(t, client, http.MethodGet, "http://api/tags/worker/output?lines=5", "", &output)
+	if len(output) != 2 {
+		t.Fatalf("bad: %#v", output)
+	}
+
+	if status := request(t, client, http.MethodGet, "http://api/tags/nope", "", nil); status != http.StatusNotFound {
+		t.Fatalf("bad: %d", status)
+	}
+	if status := request(t, client, http.MethodPost, "http://api/tags/worker/signal?signal=NOPE", "", nil); status != http.StatusBadRequest {
+		t.Fatalf("bad: %d", status)
+	}
+
+	// the event stream sees the exit of a signal sent through the API
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := http.NewRequest(http.MethodGet, "http://api/events?tag=worker", nil)
+	response, err := client.Do(events.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer response.Body.Close()
+
+	if status := request(t, client, http.MethodPost, "http://api/tags/worker/signal?signal=SIGKILL", "", nil); status != http.StatusOK {
+		t.Fatalf("bad: %d", status)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	select {
+	case line := <-lines:
+		if line != "event: exited" {
+			t.Fatalf("bad: %s", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+}
+
+func TestAPIOverTCP(t *testing.T) {
+	monitor := procMon.New()
+
+	if err := api.New(monitor, api.Options{Network: api.NetworkTCP, Address: "127.0.0.1:0"}).Start(); err != api.ErrTokenRequired {
+		t.Fatalf("bad: %v", err)
+	}
+	if err := api.New(monitor, api.Options{Network: api.NetworkTCP, Address: "0.0.0.0:0", Token: "secret"}).Start(); err != api.ErrNotLoopback {
+		t.Fatalf("bad: %v", err)
+	}
+
+	server := api.New(monitor, api.Options{Network: api.NetworkTCP, Address: "127.0.0.1:0", Token: "secret"})
+	if err := server.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer server.Stop()
+
+	url := "http://" + server.Addr().String() + "/tags"
+	if status := request(t, http.DefaultClient, http.MethodGet, url, "", nil); status != http.StatusUnauthorized {
+		t.Fatalf("bad: %d", status)
+	}
+	if status := request(t, http.DefaultClient, http.MethodGet, url, "wrong", nil); status != http.StatusUnauthorized {
+		t.Fatalf("bad: %d", status)
+	}
+	if status := request(t, http.DefaultClient, http.MethodGet, url, "secret", nil); status != http.StatusOK {
+		t.Fatalf("bad: %d", status)
+	}
+}
+
+func request(t *testing.T, client *http.Client, method string, url string, token string, result interface{}) int {
+	r, _ := http.NewRequest(method, url, strings.NewReader(""))
+	if len(token) > 0 {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := client.Do(r)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer response.Body.Close()
+
+	if result != nil {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	return response.StatusCode
+}
@@ -0,0 +1,280 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronHorizon - how far ahead a cron expression is searched, covers leap days
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// schedule - tells when a scheduled tag runs next
+type schedule interface {
+	next(after time.Time) time.Time
+}
+
+// everySchedule - `@every <duration>`, counted from the previous run
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (thisRef everySchedule) next(after time.Time) time.Time {
+	return after.Add(thisRef.interval)
+}
+
+// cronSchedule - the sets of minutes, hours, days of month, months and days of week a run can start in
+type cronSchedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	anyDay      bool // one of days of month or days of week is `*`, so a day has to match both
+}
+
+// next - the first matching minute after `after`, the zero time if there is none in the horizon
+func (thisRef cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	end := after.Add(cronHorizon)
+
+	for t.Before(end) {
+		if !thisRef.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !thisRef.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !thisRef.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !thisRef.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchesDay - like cron, if both day fields are restricted a day matching either one is enough
+func (thisRef cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := thisRef.daysOfMonth[t.Day()]
+	dayOfWeek := thisRef.daysOfWeek[int(t.Weekday())]
+
+	if thisRef.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseSchedule - a 5 field cron expression, one of the `@daily` like descriptors, `@every <duration>`
+// or `@at HH:MM[,HH:MM...]` for fixed times of day
+func parseSchedule(spec string) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("bad schedule [%s], %s", spec, err.Error())
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("bad schedule [%s], the interval must be positive", spec)
+		}
+
+		return everySchedule{interval: interval}, nil
+	}
+
+	if strings.HasPrefix(spec, "@at ") {
+		return parseTimesOfDay(spec, strings.TrimSpace(strings.TrimPrefix(spec, "@at ")))
+	}
+
+	if expression, ok := cronDescriptors[spec]; ok {
+		spec = expression
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("bad schedule [%s], expected 5 fields", spec)
+	}
+
+	cron := cronSchedule{}
+	var err error
+	if cron.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("bad schedule [%s], minutes: %s", spec, err.Error())
+	}
+	if cron.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("bad schedule [%s], hours: %s", spec, err.Error())
+	}
+	if cron.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("bad schedule [%s], days of month: %s", spec, err.Error())
+	}
+	if cron.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("bad schedule [%s], months: %s", spec, err.Error())
+	}
+	if cron.daysOfWeek, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("bad schedule [%s], days of week: %s", spec, err.Error())
+	}
+
+	// 7 is Sunday too
+	if cron.daysOfWeek[7] {
+		cron.daysOfWeek[0] = true
+	}
+
+	cron.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+
+	return cron, nil
+}
+
+// parseCronField - a comma separated list of `*`, `N`, `N-M`, each with an optional `/step`
+func parseCronField(field string, min int, max int, names map[string]int) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart := part
+		step := 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("bad step [%s]", part)
+			}
+			rangePart = part[:i]
+		}
+
+		from, to := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+
+			var err error
+			if from, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return nil, err
+			}
+
+			to = from
+			if len(bounds) > 1 {
+				if to, err = parseCronValue(bounds[1], min, max, names); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// `N/step` runs from N to the end
+				to = max
+			}
+
+			if to < from {
+				return nil, fmt.Errorf("bad range [%s]", rangePart)
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToLower(value)]; ok {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, fmt.Errorf("bad value [%s], expected %d to %d", value, min, max)
+	}
+
+	return number, nil
+}
+
+// parseTimesOfDay - `HH:MM[,HH:MM...]` as a cron schedule running every day
+func parseTimesOfDay(spec string, times string) (schedule, error) {
+	cron := cronSchedule{
+		minutes:     map[int]bool{},
+		hours:       map[int]bool{},
+		daysOfMonth: map[int]bool{},
+		months:      map[int]bool{},
+		daysOfWeek:  map[int]bool{},
+		anyDay:      true,
+	}
+
+	for day := 1; day <= 31; day++ {
+		cron.daysOfMonth[day] = true
+	}
+	for month := 1; month <= 12; month++ {
+		cron.months[month] = true
+	}
+	for day := 0; day <= 6; day++ {
+		cron.daysOfWeek[day] = true
+	}
+
+	// a cron schedule is a cross product of hours and minutes, so each time of day gets its own
+	schedules := multiSchedule{}
+	for _, timeOfDay := range strings.Split(times, ",") {
+		at, err := time.Parse("15:04", strings.TrimSpace(timeOfDay))
+		if err != nil {
+			return nil, fmt.Errorf("bad schedule [%s], expected HH:MM", spec)
+		}
+
+		one := cron
+		one.hours = map[int]bool{at.Hour(): true}
+		one.minutes = map[int]bool{at.Minute(): true}
+		schedules = append(schedules, one)
+	}
+
+	return schedules, nil
+}
+
+// multiSchedule - the earliest of several schedules
+type multiSchedule []schedule
+
+func (thisRef multiSchedule) next(after time.Time) time.Time {
+	earliest := time.Time{}
+	for _, one := range thisRef {
+		next := one.next(after)
+		if !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
+	}
+
+	return earliest
+}
+
+// NextRun - when a tag scheduled with `spec` would run next after `after`
+func NextRun(spec string, after time.Time) (time.Time, error) {
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return s.next(after), nil
+}
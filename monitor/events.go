@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"context"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// eventsBufferSize - events for a subscriber that falls this far behind are dropped
+const eventsBufferSize = 100
+
+// Events - subscribes to what happens to the monitored processes until `ctx` is done
+func (thisRef *processMonitor) Events(ctx context.Context) <-chan contracts.MonitorEvent {
+	events := make(chan contracts.MonitorEvent, eventsBufferSize)
+
+	thisRef.eventsSync.Lock()
+	thisRef.subscribers[events] = struct{}{}
+	thisRef.eventsSync.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		thisRef.eventsSync.Lock()
+		delete(thisRef.subscribers, events)
+		close(events)
+		thisRef.eventsSync.Unlock()
+	}()
+
+	return events
+}
+
+// emit - sends `event` to every subscriber, never blocks
+func (thisRef *processMonitor) emit(event contracts.MonitorEvent) {
+	thisRef.eventsSync.Lock()
+	defer thisRef.eventsSync.Unlock()
+
+	for events := range thisRef.subscribers {
+		select {
+		case events <- event:
+		default:
+			logging.Warningf("%s: event-DROPPED %s for %s, subscriber is full", logID, event.Type, event.Tag)
+		}
+	}
+}
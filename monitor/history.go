@@ -0,0 +1,31 @@
+package monitor
+
+import (
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// historySize - runs kept per tag
+const historySize = 20
+
+// History - how the recent runs of `tag` ended, oldest first
+func (thisRef *processMonitor) History(tag string) []contracts.ProcessExitResult {
+	thisRef.procsSync.Lock()
+	defer thisRef.procsSync.Unlock()
+
+	results := make([]contracts.ProcessExitResult, len(thisRef.history[tag]))
+	copy(results, thisRef.history[tag])
+
+	return results
+}
+
+func (thisRef *processMonitor) recordRun(tag string, result contracts.ProcessExitResult) {
+	thisRef.procsSync.Lock()
+	defer thisRef.procsSync.Unlock()
+
+	runs := append(thisRef.history[tag], result)
+	if len(runs) > historySize {
+		runs = runs[len(runs)-historySize:]
+	}
+
+	thisRef.history[tag] = runs
+}
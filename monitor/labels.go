@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"syscall"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// SetLabels - replaces the labels of `tag`, they start as the `Labels` of its template
+func (thisRef *processMonitor) SetLabels(tag string, labels map[string]string) error {
+	thisRef.procsSync.Lock()
+	defer thisRef.procsSync.Unlock()
+
+	processTemplate, ok := thisRef.templates[tag]
+	if !ok {
+		return contracts.ErrProcessDoesNotExist
+	}
+
+	processTemplate.Labels = copyLabels(labels)
+	thisRef.templates[tag] = processTemplate
+
+	return nil
+}
+
+// Labels - the labels of `tag`, empty if it is not monitored
+func (thisRef *processMonitor) Labels(tag string) map[string]string {
+	thisRef.procsSync.Lock()
+	defer thisRef.procsSync.Unlock()
+
+	return copyLabels(thisRef.templates[tag].Labels)
+}
+
+// Select - the sorted tags whose labels match `selectorSpec`, see `parseSelector()` for the syntax
+func (thisRef *processMonitor) Select(selectorSpec string) ([]string, error) {
+	s, err := parseSelector(selectorSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	thisRef.procsSync.Lock()
+	defer thisRef.procsSync.Unlock()
+
+	tags := []string{}
+	for tag := range thisRef.procs {
+		if s.matches(thisRef.templates[tag].Labels) {
+			tags = append(tags, tag)
+		}
+	}
+
+	sort.Strings(tags)
+
+	return tags, nil
+}
+
+// StopSelected - stops the tags matching `selectorSpec` in parallel
+func (thisRef *processMonitor) StopSelected(selectorSpec string) ([]contracts.MonitorBulkResult, error) {
+	return thisRef.forSelected(selectorSpec, thisRef.Stop)
+}
+
+// RestartSelected - restarts the tags matching `selectorSpec` in parallel
+func (thisRef *processMonitor) RestartSelected(selectorSpec string) ([]contracts.MonitorBulkResult, error) {
+	return thisRef.forSelected(selectorSpec, thisRef.Restart)
+}
+
+// SignalSelected - sends `sig` to the tags matching `selectorSpec` in parallel
+func (thisRef *processMonitor) SignalSelected(selectorSpec string, sig syscall.Signal) ([]contracts.MonitorBulkResult, error) {
+	return thisRef.forSelected(selectorSpec, func(tag string) error {
+		return thisRef.Signal(tag, sig)
+	})
+}
+
+// RemoveSelected - removes the tags matching `selectorSpec` from the monitor, like `RemoveFromMonitor()` it doesn't stop them
+func (thisRef *processMonitor) RemoveSelected(selectorSpec string) ([]contracts.MonitorBulkResult, error) {
+	return thisRef.forSelected(selectorSpec, func(tag string) error {
+		thisRef.RemoveFromMonitor(tag)
+		return nil
+	})
+}
+
+// forSelected - runs `operation` for each matching tag in parallel, the results are in tag order
+func (thisRef *processMonitor) forSelected(selectorSpec string, operation func(tag string) error) ([]contracts.MonitorBulkResult, error) {
+	tags, err := thisRef.Select(selectorSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Debugf("%s: bulk [%s] on %v", logID, selectorSpec, tags)
+
+	results := make([]contracts.MonitorBulkResult, len(tags))
+
+	wg := sync.WaitGroup{}
+	for i, tag := range tags {
+		wg.Add(1)
+		go func(i int, tag string) {
+			defer wg.Done()
+
+			results[i].Tag = tag
+			if err := operation(tag); err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, tag)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range labels {
+		result[k] = v
+	}
+
+	return result
+}
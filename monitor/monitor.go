@@ -3,6 +3,7 @@ package monitor
 import (
 	"fmt"
 	"sync"
+	"syscall"
 	"time"
 
 	logging "github.com/remoteit/systemkit-logging"
@@ -16,16 +17,30 @@ const logID = "PROCESS-MONITOR"
 // processMonitor - Represents Windows service
 type processMonitor struct {
 	procs        map[string]contracts.RuningProcess
+	templates    map[string]contracts.ProcessTemplate
+	schedules    map[string]chan struct{}
+	history      map[string][]contracts.ProcessExitResult
+	pools        map[string]*pool
+	poolsSync    *sync.Mutex
 	procsSync    *sync.Mutex
 	procTagIndex int64
+	subscribers  map[chan contracts.MonitorEvent]struct{}
+	eventsSync   *sync.Mutex
 }
 
 // New -
 func New() contracts.Monitor {
 	return &processMonitor{
 		procs:        map[string]contracts.RuningProcess{},
+		templates:    map[string]contracts.ProcessTemplate{},
+		schedules:    map[string]chan struct{}{},
+		history:      map[string][]contracts.ProcessExitResult{},
+		pools:        map[string]*pool{},
+		poolsSync:    &sync.Mutex{},
 		procsSync:    &sync.Mutex{},
 		procTagIndex: 0,
+		subscribers:  map[chan contracts.MonitorEvent]struct{}{},
+		eventsSync:   &sync.Mutex{},
 	}
 }
 
@@ -46,7 +61,12 @@ func (thisRef *processMonitor) SpawnWithTag(processTemplate contracts.ProcessTem
 	logging.Debugf("%s: spawn %s, %s", logID, tag, helpers.AsJSONString(processTemplate))
 
 	thisRef.procsSync.Lock()
+	if stop, ok := thisRef.schedules[tag]; ok {
+		close(stop)
+		delete(thisRef.schedules, tag)
+	}
 	thisRef.procs[tag] = internal.NewRuningProcess(processTemplate)
+	thisRef.templates[tag] = processTemplate
 	thisRef.procsSync.Unlock()
 
 	return thisRef.Start(tag)
@@ -60,7 +80,29 @@ func (thisRef *processMonitor) Start(tag string) error {
 	}
 
 	logging.Debugf("%s: start %s", logID, tag)
-	return rp.Start()
+	err := rp.Start()
+	if err != nil {
+		return err
+	}
+
+	thisRef.procsSync.Lock()
+	processTemplate, ok := thisRef.templates[tag]
+	thisRef.procsSync.Unlock()
+
+	if ok {
+		exited, result := internal.RuningProcessCurrentRun(rp)
+
+		thisRef.emit(contracts.MonitorEvent{
+			Type:      contracts.MonitorEventStarted,
+			Tag:       tag,
+			Time:      time.Now(),
+			ProcessID: result().ProcessID,
+		})
+
+		go thisRef.supervise(tag, rp, exited, result, processTemplate)
+	}
+
+	return nil
 }
 
 // Stop -
@@ -83,6 +125,35 @@ func (thisRef *processMonitor) Restart(tag string) error {
 	return thisRef.Start(tag)
 }
 
+// Signal - sends `sig` to the process taged with `tag`
+func (thisRef *processMonitor) Signal(tag string, sig syscall.Signal) error {
+	logging.Debugf("%s: signal %s [%s]", logID, tag, sig)
+	return thisRef.GetProcess(tag).Signal(sig)
+}
+
+// Pause - suspends the process taged with `tag`
+func (thisRef *processMonitor) Pause(tag string) error {
+	logging.Debugf("%s: pause %s", logID, tag)
+	return thisRef.GetProcess(tag).Pause()
+}
+
+// Resume - continues the process taged with `tag`
+func (thisRef *processMonitor) Resume(tag string) error {
+	logging.Debugf("%s: resume %s", logID, tag)
+	return thisRef.GetProcess(tag).Resume()
+}
+
+// Reload - sends the reload signal of its template to the process taged with `tag`
+func (thisRef *processMonitor) Reload(tag string) error {
+	logging.Debugf("%s: reload %s", logID, tag)
+	return thisRef.GetProcess(tag).Reload()
+}
+
+// Output - the last `lines` lines the process taged with `tag` wrote, with `OutputTailLines` in its template
+func (thisRef *processMonitor) Output(tag string, lines int) []contracts.ProcessOutputLine {
+	return thisRef.GetProcess(tag).Output(lines)
+}
+
 // StopAll -
 func (thisRef *processMonitor) StopAllInParallel() {
 	thisRef.procsSync.Lock()
@@ -115,6 +186,13 @@ func (thisRef *processMonitor) RemoveFromMonitor(tag string) {
 
 	if _, ok := thisRef.procs[tag]; ok {
 		delete(thisRef.procs, tag) // delete
+		delete(thisRef.templates, tag)
+		delete(thisRef.history, tag)
+	}
+
+	if stop, ok := thisRef.schedules[tag]; ok {
+		close(stop)
+		delete(thisRef.schedules, tag)
 	}
 }
 
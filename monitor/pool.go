@@ -0,0 +1,228 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// pool - a template run as numbered replicas, tagged `<name>-<index>`
+type pool struct {
+	template contracts.ProcessTemplate
+	replicas int // guarded by `poolsSync`
+
+	// serializes scaling, held through the slow stops, unlike `poolsSync`
+	scaleSync *sync.Mutex
+}
+
+func poolInstanceTag(name string, index int) string {
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
+// SpawnPool - spawns `replicas` instances of `processTemplate` tagged `name-0` to `name-<replicas-1>`,
+// spawning a pool again replaces its instances
+func (thisRef *processMonitor) SpawnPool(name string, processTemplate contracts.ProcessTemplate, replicas int) error {
+	if replicas < 0 {
+		return fmt.Errorf("bad replica count [%d] for pool [%s]", replicas, name)
+	}
+
+	p := &pool{
+		template:  processTemplate,
+		replicas:  0,
+		scaleSync: &sync.Mutex{},
+	}
+	p.scaleSync.Lock()
+	defer p.scaleSync.Unlock()
+
+	thisRef.poolsSync.Lock()
+	existing, ok := thisRef.pools[name]
+	thisRef.pools[name] = p
+	thisRef.poolsSync.Unlock()
+
+	// the new instances reuse the tags, so the old ones have to be gone first
+	if ok {
+		existing.scaleSync.Lock()
+		thisRef.scaleDown(name, existing, 0)
+		existing.scaleSync.Unlock()
+	}
+
+	return thisRef.scaleUp(name, p, replicas)
+}
+
+// Scale - spawns the missing instances of pool `name` in index order, or stops and removes
+// the ones over `replicas`
+func (thisRef *processMonitor) Scale(name string, replicas int) error {
+	if replicas < 0 {
+		return fmt.Errorf("bad replica count [%d] for pool [%s]", replicas, name)
+	}
+
+	p, err := thisRef.lockPool(name)
+	if err != nil {
+		return err
+	}
+	defer p.scaleSync.Unlock()
+
+	thisRef.poolsSync.Lock()
+	current := p.replicas
+	thisRef.poolsSync.Unlock()
+
+	logging.Debugf("%s: scale %s from %d to %d", logID, name, current, replicas)
+
+	if replicas < current {
+		thisRef.scaleDown(name, p, replicas)
+		return nil
+	}
+
+	return thisRef.scaleUp(name, p, replicas)
+}
+
+// lockPool - the pool `name` with its `scaleSync` held, a pool replaced meanwhile by `SpawnPool()` is skipped
+func (thisRef *processMonitor) lockPool(name string) (*pool, error) {
+	for {
+		thisRef.poolsSync.Lock()
+		p, ok := thisRef.pools[name]
+		thisRef.poolsSync.Unlock()
+
+		if !ok {
+			return nil, contracts.ErrPoolDoesNotExist
+		}
+
+		p.scaleSync.Lock()
+
+		thisRef.poolsSync.Lock()
+		current := thisRef.pools[name] == p
+		thisRef.poolsSync.Unlock()
+
+		if current {
+			return p, nil
+		}
+
+		p.scaleSync.Unlock()
+	}
+}
+
+// PoolStatus - the instances of pool `name` and how many of them run
+func (thisRef *processMonitor) PoolStatus(name string) (contracts.ProcessPoolStatus, error) {
+	thisRef.poolsSync.Lock()
+	p, ok := thisRef.pools[name]
+	if !ok {
+		thisRef.poolsSync.Unlock()
+		return contracts.ProcessPoolStatus{}, contracts.ErrPoolDoesNotExist
+	}
+	replicas := p.replicas
+	thisRef.poolsSync.Unlock()
+
+	// the instances are queried without `poolsSync`, their own locks may wait on a reaper
+	status := contracts.ProcessPoolStatus{
+		Name:      name,
+		Replicas:  replicas,
+		Running:   0,
+		Instances: []contracts.ProcessPoolInstance{},
+	}
+
+	for i := 0; i < replicas; i++ {
+		tag := poolInstanceTag(name, i)
+		rp := thisRef.GetProcess(tag)
+
+		instance := contracts.ProcessPoolInstance{
+			Tag:       tag,
+			Index:     i,
+			Running:   rp.IsRunning(),
+			ProcessID: rp.ExitResult().ProcessID,
+		}
+
+		if instance.Running {
+			status.Running++
+		}
+
+		status.Instances = append(status.Instances, instance)
+	}
+
+	return status, nil
+}
+
+// scaleUp - a replica that fails to spawn is removed, the pool keeps the ones before it
+func (thisRef *processMonitor) scaleUp(name string, p *pool, replicas int) error {
+	thisRef.poolsSync.Lock()
+	current := p.replicas
+	thisRef.poolsSync.Unlock()
+
+	for i := current; i < replicas; i++ {
+		tag := poolInstanceTag(name, i)
+
+		err := thisRef.SpawnWithTag(poolInstanceTemplate(p.template, name, i), tag)
+		if err != nil {
+			logging.Errorf("%s: scale-up-FAIL %s, [%s]", logID, tag, err.Error())
+
+			thisRef.Stop(tag)
+			thisRef.RemoveFromMonitor(tag)
+
+			return err
+		}
+
+		thisRef.poolsSync.Lock()
+		p.replicas = i + 1
+		thisRef.poolsSync.Unlock()
+	}
+
+	return nil
+}
+
+// scaleDown - the replicas over `replicas` leave the pool right away and are stopped in parallel
+func (thisRef *processMonitor) scaleDown(name string, p *pool, replicas int) {
+	thisRef.poolsSync.Lock()
+	current := p.replicas
+	p.replicas = replicas
+	thisRef.poolsSync.Unlock()
+
+	wg := &sync.WaitGroup{}
+	for i := current - 1; i >= replicas; i-- {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+
+			if err := thisRef.Stop(tag); err != nil {
+				logging.Warningf("%s: scale-down-stop-FAIL %s, [%s]", logID, tag, err.Error())
+			}
+			thisRef.RemoveFromMonitor(tag)
+		}(poolInstanceTag(name, i))
+	}
+	wg.Wait()
+}
+
+// poolInstancePlaceholders - what `{{.Index}}`, `{{.Name}}` and `{{.Tag}}` expand to in the args and environment
+// of a replica, nothing else is touched, so args that carry templates of their own like `--format '{{.ID}}'` pass through
+func poolInstancePlaceholders(name string, index int) *strings.Replacer {
+	return strings.NewReplacer(
+		"{{.Index}}", strconv.Itoa(index),
+		"{{.Name}}", name,
+		"{{.Tag}}", poolInstanceTag(name, index),
+	)
+}
+
+// poolInstanceTemplate - `processTemplate` with the placeholders in its args and environment expanded for replica `index`
+func poolInstanceTemplate(processTemplate contracts.ProcessTemplate, name string, index int) contracts.ProcessTemplate {
+	placeholders := poolInstancePlaceholders(name, index)
+
+	processTemplate.Args = expandPoolPlaceholders(processTemplate.Args, placeholders)
+	processTemplate.Environment = expandPoolPlaceholders(processTemplate.Environment, placeholders)
+
+	return processTemplate
+}
+
+func expandPoolPlaceholders(values []string, placeholders *strings.Replacer) []string {
+	if values == nil {
+		return nil
+	}
+
+	expanded := make([]string, 0, len(values))
+	for _, value := range values {
+		expanded = append(expanded, placeholders.Replace(value))
+	}
+
+	return expanded
+}
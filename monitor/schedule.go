@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"math/rand"
+	"time"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/helpers"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// Schedule - monitors a process that starts on `schedule` instead of right away, scheduling a tag again replaces
+// its schedule and stops what runs under the tag, a scheduled run or a spawned process alike
+func (thisRef *processMonitor) Schedule(processTemplate contracts.ProcessTemplate, tag string, schedule contracts.ProcessSchedule) error {
+	s, err := parseSchedule(schedule.Spec)
+	if err != nil {
+		return err
+	}
+
+	logging.Debugf("%s: schedule %s [%s], %s", logID, tag, schedule.Spec, helpers.AsJSONString(processTemplate))
+
+	stop := make(chan struct{})
+
+	thisRef.procsSync.Lock()
+	if previous, ok := thisRef.schedules[tag]; ok {
+		close(previous)
+		delete(thisRef.schedules, tag)
+	}
+	previous, exists := thisRef.procs[tag]
+	thisRef.procsSync.Unlock()
+
+	// replacing the entry would leave the process out of reach of `Stop()` and the overlap policies
+	if exists {
+		if err := previous.Stop(tag, 3, 0); err != nil {
+			logging.Warningf("%s: schedule %s, stop-previous-FAIL [%s]", logID, tag, err.Error())
+		}
+	}
+
+	thisRef.procsSync.Lock()
+	thisRef.procs[tag] = internal.NewRuningProcess(processTemplate)
+	thisRef.templates[tag] = processTemplate
+	thisRef.schedules[tag] = stop
+	thisRef.procsSync.Unlock()
+
+	go thisRef.runSchedule(tag, s, schedule, stop)
+
+	return nil
+}
+
+// runSchedule - starts `tag` each time `s` is due, until `stop` is closed
+func (thisRef *processMonitor) runSchedule(tag string, s schedule, schedule contracts.ProcessSchedule, stop chan struct{}) {
+	now := time.Now()
+	jitter := rand.New(rand.NewSource(now.UnixNano()))
+
+	if schedule.MissedRuns == contracts.MissedRunsRunOnce && !schedule.LastRunAt.IsZero() {
+		missed := s.next(schedule.LastRunAt)
+		if !missed.IsZero() && missed.Before(now) {
+			logging.Infof("%s: schedule %s, missed the run due at %s", logID, tag, missed.Format(time.RFC3339))
+			thisRef.scheduledRun(tag, schedule, stop)
+		}
+	}
+
+	last := now
+	for {
+		next := s.next(last)
+		if !next.IsZero() && next.Before(time.Now()) {
+			// a queued run took so long that more were due, they are merged into it
+			next = s.next(time.Now())
+		}
+		if next.IsZero() {
+			logging.Errorf("%s: schedule %s [%s] never runs", logID, tag, schedule.Spec)
+			return
+		}
+
+		delay := time.Until(next)
+		if schedule.Jitter > 0 {
+			delay += time.Duration(jitter.Int63n(int64(schedule.Jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			thisRef.scheduledRun(tag, schedule, stop)
+			last = next
+
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// scheduledRun - starts `tag` if the overlap policy allows it
+func (thisRef *processMonitor) scheduledRun(tag string, schedule contracts.ProcessSchedule, stop chan struct{}) {
+	rp := thisRef.GetProcess(tag)
+
+	if rp.IsRunning() {
+		switch schedule.Overlap {
+		case contracts.OverlapQueue:
+			logging.Debugf("%s: schedule %s, queued behind the previous run", logID, tag)
+			select {
+			case <-rp.Exited():
+			case <-stop:
+				return
+			}
+
+		case contracts.OverlapKill:
+			logging.Debugf("%s: schedule %s, stopping the previous run", logID, tag)
+			if err := thisRef.Stop(tag); err != nil {
+				logging.Errorf("%s: schedule %s, stop-FAIL [%s]", logID, tag, err.Error())
+				return
+			}
+
+		default:
+			logging.Warningf("%s: schedule %s, skipped, the previous run is still going", logID, tag)
+			return
+		}
+	}
+
+	if err := thisRef.Start(tag); err != nil {
+		logging.Errorf("%s: schedule %s, start-FAIL [%s]", logID, tag, err.Error())
+	}
+}
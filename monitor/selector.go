@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// selectorOperator - how a requirement compares the value of its label
+type selectorOperator int
+
+const (
+	selectorEquals selectorOperator = iota
+	selectorNotEquals
+	selectorIn
+	selectorNotIn
+	selectorExists
+	selectorDoesNotExist
+)
+
+// selectorRequirement - one comma separated part of a selector
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	values   map[string]bool
+}
+
+// selector - Kubernetes style label selector, all requirements have to match
+type selector []selectorRequirement
+
+var (
+	selectorSetRequirement = regexp.MustCompile(`^(\S+?)\s+(in|notin)\s*\((.*)\)$`)
+	selectorKey            = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+)
+
+// parseSelector - `key=value`, `key==value`, `key!=value`, `key in (a,b)`, `key notin (a,b)`, `key` and `!key`
+// joined by commas, an empty selector matches everything
+func parseSelector(spec string) (selector, error) {
+	parts, err := splitSelector(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	result := selector{}
+	for _, part := range parts {
+		requirement, err := parseSelectorRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, requirement)
+	}
+
+	return result, nil
+}
+
+// splitSelector - splits on the commas outside of parentheses
+func splitSelector(spec string) ([]string, error) {
+	parts := []string{}
+	if len(strings.TrimSpace(spec)) == 0 {
+		return parts, nil
+	}
+
+	depth := 0
+	start := 0
+	for i, c := range spec {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("bad selector [%s], unbalanced parentheses", spec)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(spec[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("bad selector [%s], unbalanced parentheses", spec)
+	}
+
+	return append(parts, strings.TrimSpace(spec[start:])), nil
+}
+
+func parseSelectorRequirement(part string) (selectorRequirement, error) {
+	if match := selectorSetRequirement.FindStringSubmatch(part); match != nil {
+		requirement := selectorRequirement{key: match[1], operator: selectorIn, values: map[string]bool{}}
+		if match[2] == "notin" {
+			requirement.operator = selectorNotIn
+		}
+
+		for _, value := range strings.Split(match[3], ",") {
+			requirement.values[strings.TrimSpace(value)] = true
+		}
+
+		return requirement, checkSelectorKey(requirement.key, part)
+	}
+
+	for _, op := range []struct {
+		token    string
+		operator selectorOperator
+	}{
+		{"!=", selectorNotEquals},
+		{"==", selectorEquals},
+		{"=", selectorEquals},
+	} {
+		if i := strings.Index(part, op.token); i >= 0 {
+			requirement := selectorRequirement{
+				key:      strings.TrimSpace(part[:i]),
+				operator: op.operator,
+				values:   map[string]bool{strings.TrimSpace(part[i+len(op.token):]): true},
+			}
+
+			return requirement, checkSelectorKey(requirement.key, part)
+		}
+	}
+
+	if strings.HasPrefix(part, "!") {
+		requirement := selectorRequirement{key: strings.TrimSpace(part[1:]), operator: selectorDoesNotExist}
+		return requirement, checkSelectorKey(requirement.key, part)
+	}
+
+	return selectorRequirement{key: part, operator: selectorExists}, checkSelectorKey(part, part)
+}
+
+func checkSelectorKey(key string, part string) error {
+	if !selectorKey.MatchString(key) {
+		return fmt.Errorf("bad selector requirement [%s]", part)
+	}
+
+	return nil
+}
+
+// matches - like Kubernetes, `!=` and `notin` also match labels that are not set
+func (thisRef selector) matches(labels map[string]string) bool {
+	for _, requirement := range thisRef {
+		value, ok := labels[requirement.key]
+
+		switch requirement.operator {
+		case selectorEquals, selectorIn:
+			if !ok || !requirement.values[value] {
+				return false
+			}
+		case selectorNotEquals, selectorNotIn:
+			if ok && requirement.values[value] {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"time"
+
+	logging "github.com/remoteit/systemkit-logging"
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// minStallCheckInterval - how often output is checked at most, for short `NoOutputTimeout`s
+const minStallCheckInterval = 10 * time.Millisecond
+
+// supervise - enforces the timeouts and watchdog of `processTemplate` while `rp` runs and reports its exit,
+// `exited` and `runResult` are taken when the run starts, a restart gives `rp` new ones
+func (thisRef *processMonitor) supervise(tag string, rp contracts.RuningProcess, exited <-chan struct{}, runResult func() contracts.ProcessExitResult, processTemplate contracts.ProcessTemplate) {
+	var maxRuntime <-chan time.Time
+	if processTemplate.MaxRuntime > 0 {
+		timer := time.NewTimer(processTemplate.MaxRuntime)
+		defer timer.Stop()
+
+		maxRuntime = timer.C
+	}
+
+	var stallCheck <-chan time.Time
+	if processTemplate.NoOutputTimeout > 0 {
+		interval := processTemplate.NoOutputTimeout / 4
+		if interval < minStallCheckInterval {
+			interval = minStallCheckInterval
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		stallCheck = ticker.C
+	}
+
+	var dog *watchdog
+	var watchdogCheck <-chan time.Time
+	if processTemplate.Watchdog != nil {
+		dog = newWatchdog(*processTemplate.Watchdog)
+
+		ticker := time.NewTicker(dog.interval())
+		defer ticker.Stop()
+
+		watchdogCheck = ticker.C
+	}
+
+	// a watchdog restart is a `Restart()` split in two, so the exit is reported before the new start
+	restart := false
+
+	for {
+		select {
+		case <-exited:
+			result := runResult()
+			logging.Debugf("%s: exited %s, reason [%s], code [%d]", logID, tag, result.Reason, result.ExitCode)
+			thisRef.recordRun(tag, result)
+
+			thisRef.emit(contracts.MonitorEvent{
+				Type:      contracts.MonitorEventExited,
+				Tag:       tag,
+				Time:      time.Now(),
+				ProcessID: result.ProcessID,
+				Result:    &result,
+			})
+
+			if restart {
+				if err := thisRef.Start(tag); err != nil {
+					logging.Errorf("%s: restart-FAIL %s, [%s]", logID, tag, err.Error())
+				}
+			}
+			return
+
+		case <-maxRuntime:
+			maxRuntime = nil
+
+			logging.Warningf("%s: timeout %s, ran longer than %s", logID, tag, processTemplate.MaxRuntime)
+			thisRef.stopWithReason(tag, rp, contracts.ExitReasonTimeout)
+
+		case <-stallCheck:
+			if time.Since(internal.RuningProcessLastOutputAt(rp)) < processTemplate.NoOutputTimeout {
+				continue
+			}
+			stallCheck = nil
+
+			logging.Warningf("%s: stalled %s, no output for %s", logID, tag, processTemplate.NoOutputTimeout)
+			thisRef.stopWithReason(tag, rp, contracts.ExitReasonStalled)
+
+		case now := <-watchdogCheck:
+			details := rp.Details()
+			if details.State == contracts.ProcessStateNonExistent {
+				continue
+			}
+
+			message := dog.check(details, now)
+			if len(message) == 0 {
+				continue
+			}
+
+			action := dog.action()
+			logging.Warningf("%s: watchdog %s, %s, action [%s]", logID, tag, message, action)
+
+			thisRef.emit(contracts.MonitorEvent{
+				Type:      contracts.MonitorEventWatchdog,
+				Tag:       tag,
+				Time:      now,
+				ProcessID: details.ProcessID,
+				Action:    action,
+				Message:   message,
+			})
+
+			switch action {
+			case contracts.WatchdogActionRestart:
+				watchdogCheck = nil
+				restart = true
+				thisRef.stopWithReason(tag, rp, contracts.ExitReasonWatchdog)
+
+			case contracts.WatchdogActionStop:
+				watchdogCheck = nil
+				thisRef.stopWithReason(tag, rp, contracts.ExitReasonWatchdog)
+			}
+		}
+	}
+}
+
+// stopWithReason - stops `rp` the same way `Stop()` does, with `reason` in its exit result
+func (thisRef *processMonitor) stopWithReason(tag string, rp contracts.RuningProcess, reason string) {
+	internal.SetRuningProcessExitReason(rp, reason)
+
+	err := rp.Stop(tag, 3, 0*time.Millisecond)
+	if err != nil {
+		logging.Errorf("%s: stop-FAIL %s, [%s]", logID, tag, err.Error())
+	}
+}
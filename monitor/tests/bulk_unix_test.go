@@ -0,0 +1,54 @@
+// +build !windows
+
+package tests
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestBulkSelected(t *testing.T) {
+	monitor := procMon.New()
+
+	template := contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}}
+	for _, tag := range []string{"acme-0", "acme-1", "globex-0"} {
+		template.Labels = map[string]string{"app": "tunnel", "customer": tag[:len(tag)-2]}
+		if err := monitor.SpawnWithTag(template, tag); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	defer monitor.StopSelected("")
+
+	pid := monitor.GetProcess("acme-0").ExitResult().ProcessID
+
+	results, err := monitor.RestartSelected("app=tunnel,customer=acme")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(results) != 2 || results[0].Tag != "acme-0" || results[1].Tag != "acme-1" || results[0].Error != "" {
+		t.Fatalf("bad: %#v", results)
+	}
+	if newPID := monitor.GetProcess("acme-0").ExitResult().ProcessID; newPID == pid {
+		t.Fatal("acme-0 should have restarted")
+	}
+
+	results, _ = monitor.SignalSelected("customer=globex", syscall.SIGKILL)
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("bad: %#v", results)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if monitor.GetProcess("globex-0").IsRunning() || !monitor.GetProcess("acme-1").IsRunning() {
+		t.Fatal("only globex-0 should have been killed")
+	}
+
+	monitor.StopSelected("customer=acme")
+	results, _ = monitor.RemoveSelected("app=tunnel")
+	if len(results) != 3 || len(monitor.GetAllTags()) != 0 {
+		t.Fatalf("bad: %#v", results)
+	}
+}
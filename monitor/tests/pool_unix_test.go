@@ -0,0 +1,79 @@
+// +build !windows
+
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestPoolScale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pool")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	monitor := procMon.New()
+	defer monitor.Scale("workers", 0)
+
+	err = monitor.SpawnPool("workers", contracts.ProcessTemplate{
+		Executable:  "sh",
+		Args:        []string{"-c", "echo $PORT $0 > " + filepath.Join(dir, "{{.Tag}}") + "; sleep 30", "{{.ID}}"},
+		Environment: []string{"PORT=90{{.Index}}"},
+	}, 3)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	status, err := monitor.PoolStatus("workers")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status.Replicas != 3 || status.Running != 3 {
+		t.Fatalf("bad: %#v", status)
+	}
+
+	for i, instance := range status.Instances {
+		data, _ := ioutil.ReadFile(filepath.Join(dir, instance.Tag))
+		if instance.Index != i || strings.TrimSpace(string(data)) != "90"+strconv.Itoa(i)+" {{.ID}}" {
+			t.Fatalf("bad: %#v, [%s]", instance, data)
+		}
+	}
+
+	// down removes the highest indexes first
+	if err := monitor.Scale("workers", 1); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	status, _ = monitor.PoolStatus("workers")
+	if status.Replicas != 1 || status.Running != 1 || status.Instances[0].Tag != "workers-0" {
+		t.Fatalf("bad: %#v", status)
+	}
+	if monitor.GetProcess("workers-2").IsRunning() {
+		t.Fatal("workers-2 should be gone")
+	}
+
+	if err := monitor.Scale("workers", 2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	status, _ = monitor.PoolStatus("workers")
+	if status.Replicas != 2 || status.Running != 2 || status.Instances[1].Tag != "workers-1" {
+		t.Fatalf("bad: %#v", status)
+	}
+
+	if err := monitor.Scale("nope", 1); err != contracts.ErrPoolDoesNotExist {
+		t.Fatalf("bad: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+// +build linux
+
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+	"golang.org/x/sys/unix"
+)
+
+func TestSpawnInSandbox(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to create namespaces")
+	}
+
+	hostTmpFile, err := ioutil.TempFile("", "sandbox")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	hostTmpFile.Close()
+	defer os.Remove(hostTmpFile.Name())
+
+	readOnlyDir, err := ioutil.TempDir("/var/tmp", "sandbox")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(readOnlyDir)
+
+	var lock sync.Mutex
+	output := ""
+
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable: "sh",
+		Args: []string{"-c", "echo pid=$$; ls -A /tmp | wc -l; " +
+			"touch " + filepath.Join(readOnlyDir, "file") + " 2>/dev/null && echo writable || echo readonly; sleep 30"},
+		StdoutReader: func(params interface{}, outputData []byte) {
+			lock.Lock()
+			output += string(outputData) + "\n"
+			lock.Unlock()
+		},
+		Sandbox: &contracts.ProcessSandbox{
+			Cloneflags:         unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWUTS | unix.CLONE_NEWIPC,
+			ReadOnlyBindMounts: []string{readOnlyDir},
+			PrivateTmp:         true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	time.Sleep(500 * time.Millisecond)
+
+	lock.Lock()
+	lines := strings.Fields(output)
+	lock.Unlock()
+
+	if len(lines) != 3 || lines[0] != "pid=1" || lines[1] != "0" || lines[2] != "readonly" {
+		t.Fatalf("bad: %v", lines)
+	}
+
+	if _, err := os.Stat(hostTmpFile.Name()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the host still sees the PID of its own namespace
+	rp := monitor.GetProcess(processTag)
+	details := rp.Details()
+	if details.ProcessID <= 1 || details.State == contracts.ProcessStateNonExistent {
+		t.Fatalf("bad: %#v", details)
+	}
+
+	if len(details.NamespacePIDs) != 2 || details.NamespacePIDs[0] != details.ProcessID || details.NamespacePIDs[1] != 1 {
+		t.Fatalf("bad: %v", details.NamespacePIDs)
+	}
+}
+
+func TestSpawnInChroot(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to create namespaces")
+	}
+
+	root, err := ioutil.TempDir("/var/tmp", "chroot")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	// a host folder with a mount of its own below it
+	readOnlyDir, err := ioutil.TempDir("/var/tmp", "sandbox")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(readOnlyDir)
+
+	nestedDir := filepath.Join(readOnlyDir, "nested")
+	os.Mkdir(nestedDir, 0755)
+	if err := unix.Mount("tmpfs", nestedDir, "tmpfs", 0, ""); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer unix.Unmount(nestedDir, unix.MNT_DETACH)
+
+	bindMounts := []string{"/usr", readOnlyDir}
+	for _, name := range []string{"/bin", "/lib", "/lib64"} {
+		if link, err := os.Readlink(name); err == nil {
+			os.Symlink(link, filepath.Join(root, name))
+		} else if _, err := os.Stat(name); err == nil {
+			bindMounts = append(bindMounts, name)
+		}
+	}
+
+	// `/var` of the chroot points at an absolute path, resolved on the host it is outside of the chroot
+	escape := filepath.Join("/", filepath.Base(root)+"-escape")
+	defer os.RemoveAll(escape)
+	os.Mkdir(filepath.Join(root, escape), 0755)
+	os.Symlink(escape, filepath.Join(root, "var"))
+
+	var lock sync.Mutex
+	output := ""
+
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable: "/bin/sh",
+		Args: []string{"-c", "test -d " + nestedDir + " && echo found || echo missing; " +
+			"touch " + filepath.Join(nestedDir, "file") + " 2>/dev/null && echo writable || echo readonly; sleep 30"},
+		StdoutReader: func(params interface{}, outputData []byte) {
+			lock.Lock()
+			output += string(outputData) + "\n"
+			lock.Unlock()
+		},
+		Sandbox: &contracts.ProcessSandbox{
+			Cloneflags:         unix.CLONE_NEWNS | unix.CLONE_NEWPID,
+			Chroot:             root,
+			ReadOnlyBindMounts: bindMounts,
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	lock.Lock()
+	lines := strings.Fields(output)
+	lock.Unlock()
+
+	if len(lines) != 2 || lines[0] != "found" || lines[1] != "readonly" {
+		t.Fatalf("bad: %v", lines)
+	}
+
+	if _, err := os.Lstat(escape); err == nil {
+		t.Fatalf("%s was created outside of the chroot", escape)
+	}
+
+	monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	// the targets created for the mounts are gone once the process exited
+	var left []string
+	for i := 0; i < 20; i++ {
+		time.Sleep(50 * time.Millisecond)
+		left, _ = filepath.Glob(filepath.Join(root, "*", "*"))
+		if len(left) == 0 {
+			break
+		}
+	}
+	if len(left) != 0 {
+		t.Fatalf("bad: %v", left)
+	}
+}
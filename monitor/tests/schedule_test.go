@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestNextRun(t *testing.T) {
+	// a Saturday
+	saturday := time.Date(2024, time.June, 1, 10, 7, 30, 0, time.Local)
+
+	for _, test := range []struct {
+		spec     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"*/15 * * * *", saturday, time.Date(2024, time.June, 1, 10, 15, 0, 0, time.Local)},
+		{"30 2 * * 1-5", saturday, time.Date(2024, time.June, 3, 2, 30, 0, 0, time.Local)},
+		{"0 12 1 * mon", saturday.Add(3 * time.Hour), time.Date(2024, time.June, 3, 12, 0, 0, 0, time.Local)},
+		{"0 0 29 feb *", saturday, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.Local)},
+		{"@daily", saturday, time.Date(2024, time.June, 2, 0, 0, 0, 0, time.Local)},
+		{"@at 03:15,15:45", saturday, time.Date(2024, time.June, 1, 15, 45, 0, 0, time.Local)},
+		{"@every 5m", saturday, saturday.Add(5 * time.Minute)},
+	} {
+		next, err := procMon.NextRun(test.spec, test.after)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if !next.Equal(test.expected) {
+			t.Fatalf("bad: [%s] %s, expected %s", test.spec, next, test.expected)
+		}
+	}
+
+	for _, spec := range []string{"61 * * * *", "* * *", "5-1 * * * *", "@every x", "@at 25:00"} {
+		if _, err := procMon.NextRun(spec, saturday); err == nil {
+			t.Fatalf("expected an error for [%s]", spec)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// +build !windows
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestScheduleEvery(t *testing.T) {
+	monitor := procMon.New()
+	defer monitor.RemoveFromMonitor("every")
+
+	err := monitor.Schedule(contracts.ProcessTemplate{Executable: "sh", Args: []string{"-c", "exit 2"}}, "every", contracts.ProcessSchedule{
+		Spec: "@every 200ms",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if monitor.GetProcess("every").IsRunning() {
+		t.Fatal("a scheduled tag should not start right away")
+	}
+
+	time.Sleep(900 * time.Millisecond)
+
+	history := monitor.History("every")
+	if len(history) < 2 {
+		t.Fatalf("bad: %d runs", len(history))
+	}
+
+	for _, run := range history {
+		if run.ExitCode != 2 || run.Reason != contracts.ExitReasonExited || run.Duration() < 0 {
+			t.Fatalf("bad: %#v", run)
+		}
+	}
+}
+
+func TestScheduleAgainWhileRunning(t *testing.T) {
+	monitor := procMon.New()
+	defer monitor.RemoveFromMonitor("again")
+
+	if err := monitor.SpawnWithTag(contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}}, "again"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	running := monitor.GetProcess("again")
+	if !running.IsRunning() {
+		t.Fatal("should be running")
+	}
+
+	err := monitor.Schedule(contracts.ProcessTemplate{Executable: "true"}, "again", contracts.ProcessSchedule{Spec: "@hourly"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the spawned process doesn't outlive its entry
+	select {
+	case <-running.Exited():
+	case <-time.After(2 * time.Second):
+		t.Fatal("the previous process is still running")
+	}
+
+	if monitor.GetProcess("again").IsRunning() {
+		t.Fatal("a scheduled tag should not start right away")
+	}
+}
+
+func TestScheduleOverlap(t *testing.T) {
+	monitor := procMon.New()
+	defer monitor.RemoveFromMonitor("skip")
+	defer monitor.RemoveFromMonitor("kill")
+
+	// each run takes longer than the interval
+	template := contracts.ProcessTemplate{Executable: "sleep", Args: []string{"0.35"}}
+	monitor.Schedule(template, "skip", contracts.ProcessSchedule{Spec: "@every 100ms", Overlap: contracts.OverlapSkip})
+	monitor.Schedule(template, "kill", contracts.ProcessSchedule{Spec: "@every 100ms", Overlap: contracts.OverlapKill})
+
+	time.Sleep(1200 * time.Millisecond)
+
+	skipped := monitor.History("skip")
+	if len(skipped) < 2 {
+		t.Fatalf("bad: %d runs", len(skipped))
+	}
+	for i := 1; i < len(skipped); i++ {
+		if skipped[i].StartedAt.Before(skipped[i-1].ExitedAt) || skipped[i].Reason != contracts.ExitReasonExited {
+			t.Fatalf("bad: runs overlap %#v", skipped)
+		}
+	}
+
+	killed := monitor.History("kill")
+	if len(killed) < 2 || killed[0].Reason != contracts.ExitReasonStopped {
+		t.Fatalf("bad: %#v", killed)
+	}
+}
+
+func TestScheduleMissedRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+	defer monitor.RemoveFromMonitor("missed")
+
+	monitor.Schedule(contracts.ProcessTemplate{Executable: "true"}, "missed", contracts.ProcessSchedule{
+		Spec:       "@hourly",
+		MissedRuns: contracts.MissedRunsRunOnce,
+		LastRunAt:  time.Now().Add(-2 * time.Hour),
+	})
+
+	event := nextMonitorEvent(t, events, "missed", contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonExited {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+}
@@ -0,0 +1,167 @@
+// +build linux
+
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestSpawnWithScheduling(t *testing.T) {
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable: "sleep",
+		Args:       []string{"30"},
+		Scheduling: &contracts.ProcessScheduling{
+			Nice:        5,
+			CPUAffinity: []int{0},
+			Policy:      contracts.SchedulingPolicyBatch,
+			IOClass:     contracts.IOPriorityClassIdle,
+			OOMScoreAdj: 500,
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	rp := monitor.GetProcess(processTag)
+	scheduling := rp.Details().Scheduling
+	if scheduling.Nice != 5 || len(scheduling.CPUAffinity) != 1 || scheduling.CPUAffinity[0] != 0 ||
+		scheduling.Policy != contracts.SchedulingPolicyBatch || scheduling.IOClass != contracts.IOPriorityClassIdle ||
+		scheduling.OOMScoreAdj != 500 {
+		t.Fatalf("bad: %#v", scheduling)
+	}
+
+	if err := rp.SetNice(7); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.SetSchedulingPolicy(contracts.SchedulingPolicyIdle, 0); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.SetIOPriority(contracts.IOPriorityClassBestEffort, 6); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.SetOOMScoreAdj(600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.SetSchedulingPolicy("SCHED_UNKNOWN", 0); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+
+	scheduling = rp.Details().Scheduling
+	if scheduling.Nice != 7 || scheduling.Policy != contracts.SchedulingPolicyIdle ||
+		scheduling.IOClass != contracts.IOPriorityClassBestEffort || scheduling.IOLevel != 6 ||
+		scheduling.OOMScoreAdj != 600 {
+		t.Fatalf("bad: %#v", scheduling)
+	}
+}
+
+func TestSetSchedulingAllThreads(t *testing.T) {
+	if os.Getenv("SCHEDULING_THREADS_HELPER") == "1" {
+		for i := 0; i < 4; i++ {
+			go func() {
+				runtime.LockOSThread()
+				time.Sleep(30 * time.Second)
+			}()
+		}
+		time.Sleep(30 * time.Second)
+		return
+	}
+
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable:  os.Args[0],
+		Args:        []string{"-test.run=^TestSetSchedulingAllThreads$"},
+		Environment: append(os.Environ(), "SCHEDULING_THREADS_HELPER=1"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	rp := monitor.GetProcess(processTag)
+	pid := rp.Details().ProcessID
+	taskFolder := fmt.Sprintf("/proc/%d/task", pid)
+
+	var tids []os.FileInfo
+	for i := 0; i < 50 && len(tids) < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		tids, _ = ioutil.ReadDir(taskFolder)
+	}
+	if len(tids) < 5 {
+		t.Fatalf("expected a multi-threaded child, got %d threads", len(tids))
+	}
+
+	if err := rp.SetNice(7); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := rp.SetCPUAffinity([]int{0}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tids, _ = ioutil.ReadDir(taskFolder)
+	for _, tid := range tids {
+		stat, err := ioutil.ReadFile(fmt.Sprintf("%s/%s/stat", taskFolder, tid.Name()))
+		if err != nil {
+			continue
+		}
+
+		// nice is field 19, the fields after the `comm` start at 3
+		fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+2:]))
+		if fields[19-3] != "7" {
+			t.Fatalf("thread %s has nice %s", tid.Name(), fields[19-3])
+		}
+
+		status, _ := ioutil.ReadFile(fmt.Sprintf("%s/%s/status", taskFolder, tid.Name()))
+		if !strings.Contains(string(status), "Cpus_allowed_list:\t0\n") {
+			t.Fatalf("thread %s has a different affinity", tid.Name())
+		}
+	}
+}
+
+func TestSpawnWithExplicitZeroScheduling(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to lower the OOM score")
+	}
+
+	// the child would inherit the score of the monitor
+	previous, _ := ioutil.ReadFile("/proc/self/oom_score_adj")
+	if err := ioutil.WriteFile("/proc/self/oom_score_adj", []byte("300"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer ioutil.WriteFile("/proc/self/oom_score_adj", previous, 0644)
+
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable: "sleep",
+		Args:       []string{"30"},
+		Scheduling: &contracts.ProcessScheduling{
+			Nice:           0,
+			SetNice:        true,
+			OOMScoreAdj:    0,
+			SetOOMScoreAdj: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	scheduling := monitor.GetProcess(processTag).Details().Scheduling
+	if scheduling.Nice != 0 || scheduling.OOMScoreAdj != 0 {
+		t.Fatalf("bad: %#v", scheduling)
+	}
+}
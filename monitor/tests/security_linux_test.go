@@ -0,0 +1,53 @@
+// +build linux
+
+package tests
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestSpawnWithCapabilities(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to switch users")
+	}
+
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no `nobody` user")
+	}
+
+	monitor := procMon.New()
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable:          "sleep",
+		Args:                []string{"30"},
+		User:                "nobody",
+		AmbientCapabilities: []string{"CAP_NET_BIND_SERVICE"},
+		NoNewPrivileges:     true,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	details := monitor.GetProcess(processTag).Details()
+	uid, _ := strconv.Atoi(nobody.Uid)
+
+	if details.Credentials.EffectiveUserID != uid {
+		t.Fatalf("bad: %#v", details.Credentials)
+	}
+
+	security := details.Security
+	if len(security.EffectiveCapabilities) != 1 || security.EffectiveCapabilities[0] != "CAP_NET_BIND_SERVICE" ||
+		len(security.AmbientCapabilities) != 1 || !security.NoNewPrivileges {
+		t.Fatalf("bad: %#v", security)
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestSelect(t *testing.T) {
+	monitor := procMon.New()
+
+	// scheduled far enough out to never start
+	for tag, labels := range map[string]map[string]string{
+		"tunnel-a": {"app": "tunnel", "customer": "acme", "tier": "gold"},
+		"tunnel-b": {"app": "tunnel", "customer": "globex"},
+		"web":      {"app": "web", "customer": "acme"},
+	} {
+		monitor.Schedule(contracts.ProcessTemplate{Executable: "true", Labels: labels}, tag, contracts.ProcessSchedule{Spec: "@yearly"})
+		defer monitor.RemoveFromMonitor(tag)
+	}
+
+	for selector, expected := range map[string][]string{
+		"":                                {"tunnel-a", "tunnel-b", "web"},
+		"app=tunnel":                      {"tunnel-a", "tunnel-b"},
+		"app==tunnel,customer=acme":       {"tunnel-a"},
+		"customer!=acme":                  {"tunnel-b"},
+		"customer in (acme, globex),tier": {"tunnel-a"},
+		"app notin (web),!tier":           {"tunnel-b"},
+		"app=nope":                        {},
+	} {
+		tags, err := monitor.Select(selector)
+		if err != nil {
+			t.Fatalf("[%s] err: %s", selector, err)
+		}
+
+		if !reflect.DeepEqual(tags, expected) {
+			t.Fatalf("[%s] bad: %v", selector, tags)
+		}
+	}
+
+	for _, selector := range []string{"app in (tunnel", "=tunnel", "app=tunnel,"} {
+		if _, err := monitor.Select(selector); err == nil {
+			t.Fatalf("[%s] should fail", selector)
+		}
+	}
+
+	monitor.SetLabels("web", map[string]string{"app": "tunnel"})
+	if tags, _ := monitor.Select("app=tunnel,!customer"); !reflect.DeepEqual(tags, []string{"web"}) {
+		t.Fatalf("bad: %v", tags)
+	}
+}
@@ -0,0 +1,118 @@
+// +build !windows
+
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestSignalReload(t *testing.T) {
+	output := &strings.Builder{}
+	outputSync := &sync.Mutex{}
+	reader := func(params interface{}, outputData []byte) {
+		outputSync.Lock()
+		output.Write(outputData)
+		output.WriteString("\n")
+		outputSync.Unlock()
+	}
+
+	script := "trap 'echo hup' HUP; trap 'echo usr1' USR1; trap 'echo usr2' USR2; while true; do sleep 0.05; done"
+
+	monitor := procMon.New()
+	monitor.SpawnWithTag(contracts.ProcessTemplate{Executable: "sh", Args: []string{"-c", script}, StdoutReader: reader}, "default")
+	monitor.SpawnWithTag(contracts.ProcessTemplate{Executable: "sh", Args: []string{"-c", script}, StdoutReader: reader, ReloadSignal: syscall.SIGUSR2}, "custom")
+	defer monitor.StopSelected("")
+
+	time.Sleep(200 * time.Millisecond)
+
+	for _, err := range []error{monitor.Reload("default"), monitor.Reload("custom"), monitor.Signal("default", syscall.SIGUSR1)} {
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	outputSync.Lock()
+	lines := strings.Fields(output.String())
+	outputSync.Unlock()
+
+	seen := map[string]bool{}
+	for _, line := range lines {
+		seen[line] = true
+	}
+	if len(lines) != 3 || !seen["hup"] || !seen["usr1"] || !seen["usr2"] {
+		t.Fatalf("bad: %v", lines)
+	}
+
+	if err := monitor.Signal("nope", syscall.SIGHUP); err != contracts.ErrProcessDoesNotExist {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	monitor := procMon.New()
+	monitor.SpawnWithTag(contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}}, "paused")
+	defer monitor.RemoveFromMonitor("paused")
+
+	if err := monitor.Pause("paused"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	rp := monitor.GetProcess("paused")
+	if state := rp.Details().State; state != contracts.ProcessStateTraced {
+		t.Fatalf("bad: %s", state)
+	}
+
+	if err := monitor.Resume("paused"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if state := rp.Details().State; state == contracts.ProcessStateTraced {
+		t.Fatalf("bad: %s", state)
+	}
+
+	// a paused process still stops
+	monitor.Pause("paused")
+	time.Sleep(100 * time.Millisecond)
+	monitor.StopWithTimeout("paused", 1, 100*time.Millisecond)
+
+	result := rp.ExitResult()
+	if rp.IsRunning() || result.ExitCode != -1 || result.Reason != contracts.ExitReasonStopped {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestStopDoesNotResumeUnpaused(t *testing.T) {
+	traps, err := ioutil.TempFile("", "traps")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	traps.Close()
+	defer os.Remove(traps.Name())
+
+	script := "trap 'echo cont >> " + traps.Name() + "' CONT; trap 'echo int >> " + traps.Name() + "' INT; while true; do sleep 0.05; done"
+
+	monitor := procMon.New()
+	monitor.SpawnWithTag(contracts.ProcessTemplate{Executable: "sh", Args: []string{"-c", script}}, "unpaused")
+	defer monitor.RemoveFromMonitor("unpaused")
+
+	time.Sleep(200 * time.Millisecond)
+	monitor.StopWithTimeout("unpaused", 1, 200*time.Millisecond)
+
+	data, _ := ioutil.ReadFile(traps.Name())
+	if lines := strings.Fields(string(data)); len(lines) != 1 || lines[0] != "int" {
+		t.Fatalf("bad: %v", lines)
+	}
+}
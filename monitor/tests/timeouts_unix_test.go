@@ -0,0 +1,103 @@
+// +build !windows
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestMaxRuntime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+
+	// keeps writing, so only the max runtime can stop it
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable:      "sh",
+		Args:            []string{"-c", "while true; do echo tick; sleep 0.05; done"},
+		MaxRuntime:      time.Second,
+		NoOutputTimeout: 300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	nextMonitorEvent(t, events, processTag, contracts.MonitorEventStarted)
+	event := nextMonitorEvent(t, events, processTag, contracts.MonitorEventExited)
+
+	if event.Result.Reason != contracts.ExitReasonTimeout || event.Time.Sub(event.Result.StartedAt) < time.Second {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+
+	if reason := monitor.GetProcess(processTag).ExitResult().Reason; reason != contracts.ExitReasonTimeout {
+		t.Fatalf("bad: %s", reason)
+	}
+}
+
+func TestNoOutputTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable:      "sh",
+		Args:            []string{"-c", "echo once; sleep 30"},
+		NoOutputTimeout: 300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	event := nextMonitorEvent(t, events, processTag, contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonStalled {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+}
+
+func TestExitReasons(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+
+	exitingTag, _ := monitor.Spawn(contracts.ProcessTemplate{Executable: "sh", Args: []string{"-c", "exit 3"}})
+	event := nextMonitorEvent(t, events, exitingTag, contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonExited || event.Result.ExitCode != 3 {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+
+	stoppedTag, _ := monitor.Spawn(contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}})
+	monitor.Stop(stoppedTag)
+	event = nextMonitorEvent(t, events, stoppedTag, contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonStopped {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+}
+
+// nextMonitorEvent - waits for the next event of `eventType` for `tag`, skipping others
+func nextMonitorEvent(t *testing.T, events <-chan contracts.MonitorEvent, tag string, eventType contracts.MonitorEventType) contracts.MonitorEvent {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Tag == tag && event.Type == eventType {
+				return event
+			}
+
+		case <-timeout:
+			t.Fatalf("no %s event for %s", eventType, tag)
+		}
+	}
+}
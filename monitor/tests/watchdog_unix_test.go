@@ -0,0 +1,83 @@
+// +build !windows
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	procMon "github.com/remoteit/systemkit-processes/monitor"
+)
+
+func TestWatchdogRestartsOnCPU(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+
+	processTag, err := monitor.Spawn(contracts.ProcessTemplate{
+		Executable: "sh",
+		Args:       []string{"-c", "while :; do :; done"},
+		Watchdog: &contracts.ProcessWatchdog{
+			Interval:            100 * time.Millisecond,
+			MaxCPUPercent:       50,
+			MaxCPUPercentWindow: 300 * time.Millisecond,
+			Action:              contracts.WatchdogActionRestart,
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer monitor.StopWithTimeout(processTag, 1, 100*time.Millisecond)
+
+	started := nextMonitorEvent(t, events, processTag, contracts.MonitorEventStarted)
+
+	event := nextMonitorEvent(t, events, processTag, contracts.MonitorEventWatchdog)
+	if event.Action != contracts.WatchdogActionRestart || len(event.Message) == 0 {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	event = nextMonitorEvent(t, events, processTag, contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonWatchdog {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+
+	restarted := nextMonitorEvent(t, events, processTag, contracts.MonitorEventStarted)
+	if restarted.ProcessID == started.ProcessID {
+		t.Fatalf("bad: same PID %d after restart", restarted.ProcessID)
+	}
+}
+
+func TestWatchdogOnResidentSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := procMon.New()
+	events := monitor.Events(ctx)
+
+	watchdog := contracts.ProcessWatchdog{
+		Interval:               50 * time.Millisecond,
+		MaxResidentSize:        1,
+		MaxResidentSizeSamples: 3,
+	}
+
+	warnedTag, _ := monitor.Spawn(contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}, Watchdog: &watchdog})
+	defer monitor.StopWithTimeout(warnedTag, 1, 100*time.Millisecond)
+
+	event := nextMonitorEvent(t, events, warnedTag, contracts.MonitorEventWatchdog)
+	if event.Action != contracts.WatchdogActionWarn || !monitor.GetProcess(warnedTag).IsRunning() {
+		t.Fatalf("bad: %#v", event)
+	}
+
+	stopping := watchdog
+	stopping.Action = contracts.WatchdogActionStop
+	stoppedTag, _ := monitor.Spawn(contracts.ProcessTemplate{Executable: "sleep", Args: []string{"30"}, Watchdog: &stopping})
+
+	event = nextMonitorEvent(t, events, stoppedTag, contracts.MonitorEventExited)
+	if event.Result.Reason != contracts.ExitReasonWatchdog {
+		t.Fatalf("bad: %#v", event.Result)
+	}
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// defaultWatchdogInterval - between samples when `contracts.ProcessWatchdog.Interval` is not set
+const defaultWatchdogInterval = 5 * time.Second
+
+// watchdog - the samples of one run of a process, compared against its limits
+type watchdog struct {
+	limits              contracts.ProcessWatchdog
+	residentSizeSamples int
+	cpuOverSince        time.Time
+	previousCPU         time.Duration
+	previousSample      time.Time
+}
+
+func newWatchdog(limits contracts.ProcessWatchdog) *watchdog {
+	return &watchdog{
+		limits:              limits,
+		residentSizeSamples: 0,
+		cpuOverSince:        time.Time{},
+		previousCPU:         0,
+		previousSample:      time.Time{},
+	}
+}
+
+func (thisRef *watchdog) interval() time.Duration {
+	if thisRef.limits.Interval <= 0 {
+		return defaultWatchdogInterval
+	}
+
+	return thisRef.limits.Interval
+}
+
+func (thisRef *watchdog) action() string {
+	if len(thisRef.limits.Action) == 0 {
+		return contracts.WatchdogActionWarn
+	}
+
+	return thisRef.limits.Action
+}
+
+// check - adds a sample taken at `now`, returns what went over the limits, empty if nothing did,
+// the counters start over after each violation so a warning repeats at most once per window
+func (thisRef *watchdog) check(details contracts.RuntimeProcess, now time.Time) string {
+	violations := []string{}
+
+	if thisRef.limits.MaxResidentSize > 0 {
+		if details.Memory.ResidentSize > thisRef.limits.MaxResidentSize {
+			thisRef.residentSizeSamples++
+		} else {
+			thisRef.residentSizeSamples = 0
+		}
+
+		samples := thisRef.limits.MaxResidentSizeSamples
+		if samples < 1 {
+			samples = 1
+		}
+
+		if thisRef.residentSizeSamples >= samples {
+			violations = append(violations, fmt.Sprintf("resident size %d bytes over %d for %d samples",
+				details.Memory.ResidentSize, thisRef.limits.MaxResidentSize, thisRef.residentSizeSamples))
+			thisRef.residentSizeSamples = 0
+		}
+	}
+
+	if thisRef.limits.MaxCPUPercent > 0 {
+		cpu := details.CPU.TotalTime()
+
+		if !thisRef.previousSample.IsZero() && now.After(thisRef.previousSample) {
+			percent := float64(cpu-thisRef.previousCPU) / float64(now.Sub(thisRef.previousSample)) * 100
+
+			if percent > thisRef.limits.MaxCPUPercent {
+				if thisRef.cpuOverSince.IsZero() {
+					thisRef.cpuOverSince = thisRef.previousSample
+				}
+
+				if now.Sub(thisRef.cpuOverSince) >= thisRef.limits.MaxCPUPercentWindow {
+					violations = append(violations, fmt.Sprintf("CPU %.1f%% over %.1f%% for %s",
+						percent, thisRef.limits.MaxCPUPercent, now.Sub(thisRef.cpuOverSince).Round(time.Millisecond)))
+					thisRef.cpuOverSince = time.Time{}
+				}
+			} else {
+				thisRef.cpuOverSince = time.Time{}
+			}
+		}
+
+		thisRef.previousCPU = cpu
+		thisRef.previousSample = now
+	}
+
+	return strings.Join(violations, ", ")
+}
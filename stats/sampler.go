@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/internal"
+)
+
+// processSampler - keeps the previous snapshot around to turn counters into rates
+type processSampler struct {
+	sortOrder contracts.SampleSortOrder
+	pids      []int
+
+	previous       map[string]contracts.RuntimeProcess // by `Identity`, a recycled PID never gets a delta
+	previousTicks  uint64
+	previousSample time.Time
+	sampleSync     *sync.Mutex
+
+	stopChan chan bool
+	stopSync *sync.Mutex
+}
+
+// NewSampler - samples the processes with `pids`, or all processes if none are given
+func NewSampler(sortOrder contracts.SampleSortOrder, pids ...int) contracts.Sampler {
+	return &processSampler{
+		sortOrder:      sortOrder,
+		pids:           pids,
+		previous:       map[string]contracts.RuntimeProcess{},
+		previousTicks:  0,
+		previousSample: time.Unix(0, 0),
+		sampleSync:     &sync.Mutex{},
+		stopChan:       nil,
+		stopSync:       &sync.Mutex{},
+	}
+}
+
+// Sample - takes a snapshot and computes rates against the previous one,
+// processes seen for the first time report zero rates
+func (thisRef *processSampler) Sample() ([]contracts.ProcessSample, error) {
+	thisRef.sampleSync.Lock()
+	defer thisRef.sampleSync.Unlock()
+
+	runtimeProcesses, err := thisRef.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	totalTicks, numCPU, ticksErr := internal.GetSystemCPUTicks()
+	memoryTotal, _ := internal.GetSystemMemoryTotal()
+
+	elapsed := now.Sub(thisRef.previousSample).Seconds()
+	deltaTicks := float64(totalTicks - thisRef.previousTicks)
+
+	current := make(map[string]contracts.RuntimeProcess, len(runtimeProcesses))
+	samples := make([]contracts.ProcessSample, 0, len(runtimeProcesses))
+	for _, rp := range runtimeProcesses {
+		current[rp.Identity] = rp
+
+		sample := contracts.ProcessSample{
+			Process: rp,
+		}
+
+		if memoryTotal > 0 {
+			sample.MemoryPercent = float64(rp.Memory.ResidentSize) / float64(memoryTotal) * 100
+		}
+
+		if previous, ok := thisRef.previous[rp.Identity]; ok && elapsed > 0 {
+			cpuDelta := float64(rp.CPU.UserTicks + rp.CPU.SystemTicks - previous.CPU.UserTicks - previous.CPU.SystemTicks)
+
+			if ticksErr == nil && deltaTicks > 0 {
+				// process ticks vs ticks of all CPUs, scaled so one busy CPU is 100%
+				sample.CPUPercent = cpuDelta / deltaTicks * 100 * float64(numCPU)
+			} else if rp.CPU.TicksPerSecond > 0 {
+				sample.CPUPercent = cpuDelta / float64(rp.CPU.TicksPerSecond) / elapsed * 100
+			}
+
+			if rp.IO.Available && previous.IO.Available {
+				sample.ReadBytesPerSecond = float64(rp.IO.ReadBytes-previous.IO.ReadBytes) / elapsed
+				sample.WriteBytesPerSecond = float64(rp.IO.WriteBytes-previous.IO.WriteBytes) / elapsed
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	thisRef.previous = current
+	thisRef.previousTicks = totalTicks
+	thisRef.previousSample = now
+
+	SortSamples(samples, thisRef.sortOrder)
+
+	return samples, nil
+}
+
+// Start - samples every `interval` and delivers the results on the returned channel until `Stop()`,
+// a slow reader only misses samples, it never blocks the sampler
+func (thisRef *processSampler) Start(interval time.Duration) <-chan []contracts.ProcessSample {
+	thisRef.Stop()
+
+	thisRef.stopSync.Lock()
+	stopChan := make(chan bool)
+	thisRef.stopChan = stopChan
+	thisRef.stopSync.Unlock()
+
+	samplesChan := make(chan []contracts.ProcessSample, 1)
+
+	go func() {
+		defer close(samplesChan)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		thisRef.Sample() // baseline
+
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				samples, err := thisRef.Sample()
+				if err != nil {
+					continue
+				}
+
+				select {
+				case samplesChan <- samples:
+				default:
+				}
+			}
+		}
+	}()
+
+	return samplesChan
+}
+
+// Stop - stops sampling started with `Start()`
+func (thisRef *processSampler) Stop() {
+	thisRef.stopSync.Lock()
+	defer thisRef.stopSync.Unlock()
+
+	if thisRef.stopChan != nil {
+		close(thisRef.stopChan)
+		thisRef.stopChan = nil
+	}
+}
+
+func (thisRef *processSampler) snapshot() ([]contracts.RuntimeProcess, error) {
+	if len(thisRef.pids) == 0 {
+		return internal.GetAllRuntimeProcesses()
+	}
+
+	results := make([]contracts.RuntimeProcess, 0, len(thisRef.pids))
+	for _, pid := range thisRef.pids {
+		rp, err := internal.GetRuntimeProcessByPID(pid)
+		if err != nil || rp.State == contracts.ProcessStateNonExistent {
+			continue
+		}
+
+		results = append(results, rp)
+	}
+
+	return results, nil
+}
+
+// SortSamples - sorts `samples` in place
+func SortSamples(samples []contracts.ProcessSample, sortOrder contracts.SampleSortOrder) {
+	sort.SliceStable(samples, func(i, j int) bool {
+		switch sortOrder {
+		case contracts.SortByMemory:
+			return samples[i].MemoryPercent > samples[j].MemoryPercent
+		case contracts.SortByIO:
+			return samples[i].ReadBytesPerSecond+samples[i].WriteBytesPerSecond > samples[j].ReadBytesPerSecond+samples[j].WriteBytesPerSecond
+		case contracts.SortByPID:
+			return samples[i].Process.ProcessID < samples[j].Process.ProcessID
+		}
+
+		return samples[i].CPUPercent > samples[j].CPUPercent
+	})
+}
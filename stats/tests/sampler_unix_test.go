@@ -0,0 +1,63 @@
+// +build !windows
+
+package tests
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+	"github.com/remoteit/systemkit-processes/stats"
+)
+
+func TestSamplerCPU(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "while :; do :; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	sampler := stats.NewSampler(contracts.SortByCPU, cmd.Process.Pid)
+	sampler.Sample()
+	time.Sleep(1 * time.Second)
+
+	samples, err := sampler.Sample()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(samples) != 1 {
+		t.Fatalf("bad: %d samples", len(samples))
+	}
+
+	if samples[0].CPUPercent < 10 {
+		t.Fatalf("bad: CPU%% %f", samples[0].CPUPercent)
+	}
+
+	fmt.Println(stats.Top(samples, 0))
+}
+
+func TestSamplerStart(t *testing.T) {
+	sampler := stats.NewSampler(contracts.SortByMemory)
+	samplesChan := sampler.Start(200 * time.Millisecond)
+
+	samples := <-samplesChan
+	sampler.Stop()
+
+	if len(samples) == 0 {
+		t.Fatal("should have samples")
+	}
+
+	for i := 1; i < len(samples); i++ {
+		if samples[i].MemoryPercent > samples[i-1].MemoryPercent {
+			t.Fatal("should be sorted by memory")
+		}
+	}
+
+	fmt.Println(stats.Top(samples, 10))
+}
@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/remoteit/systemkit-processes/contracts"
+)
+
+// Top - renders `samples` as a `top` style table, `limit` <= 0 renders all
+func Top(samples []contracts.ProcessSample, limit int) string {
+	if limit <= 0 || limit > len(samples) {
+		limit = len(samples)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%7s %6s %6s %10s %10s %10s  %s\n", "PID", "%CPU", "%MEM", "RSS", "READ/s", "WRITE/s", "COMMAND"))
+
+	for _, sample := range samples[:limit] {
+		readRate, writeRate := "-", "-"
+		if sample.Process.IO.Available {
+			readRate = formatBytes(sample.ReadBytesPerSecond)
+			writeRate = formatBytes(sample.WriteBytesPerSecond)
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"%7d %6.1f %6.1f %10s %10s %10s  %s\n",
+			sample.Process.ProcessID,
+			sample.CPUPercent,
+			sample.MemoryPercent,
+			formatBytes(float64(sample.Process.Memory.ResidentSize)),
+			readRate,
+			writeRate,
+			sample.Process.ExecutableName,
+		))
+	}
+
+	return sb.String()
+}
+
+func formatBytes(size float64) string {
+	units := []string{"B", "K", "M", "G", "T"}
+
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.1f%s", size, units[unit])
+}